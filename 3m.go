@@ -21,6 +21,7 @@ func _3mMode() {
 		size     = 256
 		width    = 256
 		clusters = 4
+		maxK     = 8
 		samples  = 1024
 	)
 
@@ -44,7 +45,7 @@ func _3mMode() {
 	for i := range books {
 		books[i] = make(map[Markov][]float32)
 	}
-	var sets [clusters]Model
+	sets := make([]Model, clusters)
 	for i := range sets {
 		for ii := range sets[i] {
 			sets[i][ii] = make(map[Markov][]float32)
@@ -94,10 +95,11 @@ func _3mMode() {
 	type Segment struct {
 		Segment []byte
 		Rank    float64
+		Stddev  float64
 		Cluster int
 	}
 
-	rng := rand.New(rand.NewSource(1))
+	rng := rand.New(rand.NewSource(*FlagSeed))
 	for range 3 {
 		var list *Vector[Segment]
 		count := 0
@@ -187,46 +189,39 @@ func _3mMode() {
 			Iterations: 8,
 			Size:       length,
 			Divider:    0,
+			Embedding:  *FlagEmbedding,
+			Laplacian:  *FlagLaplacian,
 		}
 
-		cov := Morpheus(rng.Int63(), config, segments)
+		result := Morpheus(rng.Int63(), config, segments)
+		cov := result.Cov
 		for i := range cov {
 			l2 := 0.0
 			for _, value := range cov[i] {
 				l2 += value * value
 			}
 			segments[i].Meta.Rank = math.Sqrt(l2)
+			segments[i].Meta.Stddev = result.Stddev[i]
 		}
 
-		meta := make([][]float64, len(segments))
-		for i := range meta {
-			meta[i] = make([]float64, len(segments))
-		}
-		const k = clusters
-		for i := 0; i < 33; i++ {
-			clusters, _, err := kmeans.Kmeans(int64(i+1), cov, k, kmeans.SquaredEuclideanDistance, -1)
-			if err != nil {
-				panic(err)
-			}
-			for i := 0; i < len(meta); i++ {
-				target := clusters[i]
-				for j, v := range clusters {
-					if v == target {
-						meta[i][j]++
-					}
-				}
-			}
+		points := result.Points()
+		options := kmeans.Options{BatchSize: kmeans.BatchSize}
+		k, _, err := kmeans.SelectK(rng.Int63(), points, 2, maxK, kmeans.SelectOptions{Options: options})
+		if err != nil {
+			panic(err)
 		}
-		clusters, _, err := kmeans.Kmeans(1, meta, k, kmeans.SquaredEuclideanDistance, -1)
+		fmt.Println("k", k)
+		consensus, err := kmeans.Consensus(rng.Int63(), points, k, 33, kmeans.ConsensusOptions{Options: options})
 		if err != nil {
 			panic(err)
 		}
+		clusters := consensus.Labels
 		for i := range segments {
 			segments[i].Meta.Cluster = clusters[i]
 		}
 
 		sort.Slice(segments, func(i, j int) bool {
-			return segments[i].Stddev < segments[j].Stddev
+			return segments[i].Meta.Stddev < segments[j].Meta.Stddev
 		})
 		for i := range segments[:10] {
 			fmt.Println(string(segments[i].Meta.Segment))
@@ -234,6 +229,7 @@ func _3mMode() {
 
 		fmt.Println("---------------------------------------")
 
+		sets = make([]Model, k)
 		for i := range sets {
 			for ii := range sets[i] {
 				sets[i][ii] = make(map[Markov][]float32)
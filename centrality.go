@@ -0,0 +1,129 @@
+// Copyright 2025 The Morpheus Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+// incoming computes the sum of the absolute weights of the incoming edges of each node weighted by x
+func (m Matrix[T]) incoming(x, y []T) {
+	for i := range y {
+		y[i] = 0
+	}
+	for i := range m.Rows {
+		for ii, value := range m.Data[i*m.Cols : (i+1)*m.Cols] {
+			y[ii] += abs(finite(value)) * x[i]
+		}
+	}
+}
+
+// outgoing computes the sum of the absolute weights of the outgoing edges of each node weighted by x
+func (m Matrix[T]) outgoing(x, y []T) {
+	for i := range m.Rows {
+		var sum T
+		for ii, value := range m.Data[i*m.Cols : (i+1)*m.Cols] {
+			sum += abs(finite(value)) * x[ii]
+		}
+		y[i] = sum
+	}
+}
+
+// normalize scales x to an l1 norm of one and returns the l1 change from previous
+func normalize[T Float](x, previous []T) T {
+	var sum T
+	for _, value := range x {
+		sum += abs(value)
+	}
+	var delta T
+	for i := range x {
+		if sum > 0 {
+			x[i] /= sum
+		}
+		delta += abs(x[i] - previous[i])
+	}
+	return delta
+}
+
+// uniform returns n values of 1/n
+func uniform[T Float](n int) []T {
+	x := make([]T, n)
+	for i := range x {
+		x[i] = 1 / T(n)
+	}
+	return x
+}
+
+// HITS computes the hub and authority scores of the nodes of a graph with absolute edge weights,
+// the iteration stops when the l1 change of the scores is at most e
+func (m Matrix[T]) HITS(e T) (hubs, authorities []T) {
+	hubs, authorities = uniform[T](m.Rows), uniform[T](m.Cols)
+	h, a := make([]T, m.Rows), make([]T, m.Cols)
+	for range MaxPowerIterations {
+		m.incoming(hubs, a)
+		m.outgoing(a, h)
+		delta := normalize(a, authorities) + normalize(h, hubs)
+		hubs, h = h, hubs
+		authorities, a = a, authorities
+		if delta <= e {
+			break
+		}
+	}
+	return hubs, authorities
+}
+
+// EigenvectorCentrality computes the principal left eigenvector of a graph with absolute edge weights,
+// the iteration is shifted by the identity so that it also converges on periodic graphs
+func (m Matrix[T]) EigenvectorCentrality(e T) []T {
+	x, y := uniform[T](m.Cols), make([]T, m.Cols)
+	for range MaxPowerIterations {
+		m.incoming(x, y)
+		for i := range y {
+			y[i] += x[i]
+		}
+		delta := normalize(y, x)
+		x, y = y, x
+		if delta <= e {
+			break
+		}
+	}
+	return x
+}
+
+// Katz computes the katz centrality x = alpha A^T x + 1 of a graph with absolute edge weights and scales
+// the centrality to an l1 norm of one, alpha defaults to half of the inverse of the largest absolute row
+// or column sum which is less than the inverse of the spectral radius
+func (m Matrix[T]) Katz(alpha, e T) []T {
+	if alpha <= 0 {
+		var bound T
+		columns := make([]T, m.Cols)
+		for i := range m.Rows {
+			var sum T
+			for ii, value := range m.Data[i*m.Cols : (i+1)*m.Cols] {
+				sum += abs(finite(value))
+				columns[ii] += abs(finite(value))
+			}
+			bound = max(bound, sum)
+		}
+		for _, value := range columns {
+			bound = max(bound, value)
+		}
+		alpha = 1
+		if bound > 0 {
+			alpha = .5 / bound
+		}
+	}
+	x, y := make([]T, m.Cols), make([]T, m.Cols)
+	for range MaxPowerIterations {
+		m.incoming(x, y)
+		var delta T
+		for i := range y {
+			y[i] = alpha*y[i] + 1
+			delta += abs(y[i] - x[i])
+		}
+		x, y = y, x
+		if delta <= e {
+			break
+		}
+	}
+	normalize(x, y)
+	return x
+}
@@ -0,0 +1,99 @@
+// Copyright 2025 The Morpheus Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// principal returns the principal eigenvector of a symmetric matrix normalized to an l1 norm of one
+func principal(m Matrix[float64]) []float64 {
+	_, vectors := m.Eigen()
+	x := append([]float64{}, vectors.Data[:m.Cols]...)
+	sum := 0.0
+	for _, value := range x {
+		sum += value
+	}
+	for i := range x {
+		x[i] /= sum
+	}
+	return x
+}
+
+func TestCentrality(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	const size = 8
+	adj := NewMatrix(size, size, make([]float64, size*size)...)
+	for i := range adj.Data {
+		adj.Data[i] = rng.Float64()
+	}
+
+	hubs, authorities := adj.HITS(1e-12)
+	ata := NewMatrix(size, size, make([]float64, size*size)...)
+	aat := NewMatrix(size, size, make([]float64, size*size)...)
+	for i := range size {
+		for ii := range size {
+			for k := range size {
+				ata.Data[i*size+ii] += adj.Data[k*size+i] * adj.Data[k*size+ii]
+				aat.Data[i*size+ii] += adj.Data[i*size+k] * adj.Data[ii*size+k]
+			}
+		}
+	}
+	for i, expected := range principal(ata) {
+		if math.Abs(authorities[i]-expected) > 1e-9 {
+			t.Fatalf("authority %d: %f != %f", i, authorities[i], expected)
+		}
+	}
+	for i, expected := range principal(aat) {
+		if math.Abs(hubs[i]-expected) > 1e-9 {
+			t.Fatalf("hub %d: %f != %f", i, hubs[i], expected)
+		}
+	}
+
+	symmetric := adj.Add(adj.T())
+	for i, expected := range principal(symmetric) {
+		if value := symmetric.EigenvectorCentrality(1e-12)[i]; math.Abs(value-expected) > 1e-9 {
+			t.Fatalf("eigenvector %d: %f != %f", i, value, expected)
+		}
+	}
+
+	// the normalized katz centrality x satisfies x - alpha A^T x = c for a constant c
+	const alpha = .05
+	katz := adj.Katz(alpha, 1e-12)
+	residual := make([]float64, size)
+	adj.incoming(katz, residual)
+	c := katz[0] - alpha*residual[0]
+	for i, value := range katz {
+		if diff := math.Abs(value - alpha*residual[i] - c); diff > 1e-9 {
+			t.Fatalf("katz %d is off by %g", i, diff)
+		}
+	}
+	if defaults := adj.Katz(0, 1e-12); math.IsNaN(defaults[0]) {
+		t.Fatal("katz with the default alpha did not converge")
+	}
+}
+
+func TestMorpheusRankers(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	type T struct{}
+	vectors := normal[T](rng, 8, 8)
+	for name, ranker := range Rankers {
+		config := Config{
+			Iterations: 4,
+			Size:       8,
+			Divider:    1,
+			Accuracy:   8,
+			Ranker:     ranker,
+		}
+		result := MorpheusGramSchmidt(1, config, vectors)
+		for i, value := range result.Avg {
+			if math.IsNaN(value) || math.IsNaN(result.Cov[i][i]) {
+				t.Fatalf("%s: %d is NaN", name, i)
+			}
+		}
+	}
+}
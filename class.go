@@ -122,6 +122,7 @@ func ClassMode() {
 	}
 
 	vectorize := func(linesA, linesB []*Vector[Line], seed int64) (Matrix[float64], Matrix[float64], []int) {
+		rng := rand.New(rand.NewSource(seed))
 		lines := make([]*Vector[Line], len(linesA)+len(linesB))
 		copy(lines[:len(linesA)], linesA)
 		copy(lines[len(linesA):], linesB)
@@ -130,35 +131,23 @@ func ClassMode() {
 			Iterations: 16,
 			Size:       100,
 			Divider:    1,
+			Similarity: Kernels[*FlagKernel],
+			Ranker:     Rankers[*FlagRanker],
+			Embedding:  *FlagEmbedding,
+			Laplacian:  *FlagLaplacian,
 		}
-		cov := Morpheus(seed, config, lines)
+		result := Morpheus(rng.Int63(), config, lines)
+		cov, points := result.Cov, result.Points()
 
-		meta := make([][]float64, len(lines))
-		for i := range meta {
-			meta[i] = make([]float64, len(lines))
-		}
 		k := 2
 		if *FlagE {
 			k = 2
 		}
-		for i := 0; i < 33; i++ {
-			clusters, _, err := kmeans.Kmeans(int64(i+1), cov, k, kmeans.SquaredEuclideanDistance, -1)
-			if err != nil {
-				panic(err)
-			}
-			for i := 0; i < len(meta); i++ {
-				target := clusters[i]
-				for j, v := range clusters {
-					if v == target {
-						meta[i][j]++
-					}
-				}
-			}
-		}
-		clusters, _, err := kmeans.Kmeans(1, meta, k, kmeans.SquaredEuclideanDistance, -1)
+		consensus, err := kmeans.Consensus(rng.Int63(), points, k, 33, kmeans.ConsensusOptions{})
 		if err != nil {
 			panic(err)
 		}
+		clusters := consensus.Labels
 
 		embedding := NewMatrix(len(lines), len(linesA), make([]float64, len(lines)*len(linesA))...)
 		for i := range cov[:len(linesA)] {
@@ -177,7 +166,7 @@ func ClassMode() {
 
 	var cs [7]float64
 	var diff [7][2]float64
-	rng := rand.New(rand.NewSource(1))
+	rng := rand.New(rand.NewSource(*FlagSeed))
 	human := parse(string(data))
 	fake0 := parse(FakeText0)
 	fake1 := parse(FakeText1)
@@ -0,0 +1,137 @@
+// Copyright 2025 The Morpheus Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+// EdgeConstraint constrains the edges of the similarity graph before they are filtered, each constraint
+// is given the weight of the edge and whether it is linked as returned by the previous constraint.
+// In sparse mode only the edges of the neighbor graph are constrained
+type EdgeConstraint interface {
+	Constrain(from, to int, weight float32, linked bool) (float32, bool)
+}
+
+// ConstraintMode is how a constraint changes the edges it contains
+type ConstraintMode int
+
+const (
+	// Mask unlinks the edges that are not in the constraint
+	Mask ConstraintMode = iota
+	// Force links the edges in the constraint with the weight of the constraint
+	Force
+	// Prior adds the weight of the constraint to the edges in the constraint
+	Prior
+)
+
+// constrain applies the mode to an edge with the given constraint value
+func (m ConstraintMode) constrain(weight float32, linked bool, value float32, found bool) (float32, bool) {
+	switch m {
+	case Mask:
+		return weight, linked && found
+	case Force:
+		if found {
+			return value, true
+		}
+	case Prior:
+		if found {
+			return weight + value, linked
+		}
+	}
+	return weight, linked
+}
+
+// AdjacencyConstraint is a constraint with the edges in an adjacency map
+type AdjacencyConstraint struct {
+	Mode  ConstraintMode
+	Edges map[int]map[int]float32
+}
+
+// Constrain applies the mode if the edge is in the adjacency map
+func (a AdjacencyConstraint) Constrain(from, to int, weight float32, linked bool) (float32, bool) {
+	value, found := a.Edges[from][to]
+	return a.Mode.constrain(weight, linked, value, found)
+}
+
+// Set adds an edge to the adjacency map
+func (a *AdjacencyConstraint) Set(from, to int, value float32) {
+	if a.Edges == nil {
+		a.Edges = make(map[int]map[int]float32)
+	}
+	if a.Edges[from] == nil {
+		a.Edges[from] = make(map[int]float32)
+	}
+	a.Edges[from][to] = value
+}
+
+// NewWordConstraint masks the edges between vectors whose words are not linked in the word graph
+func NewWordConstraint[T any](vectors []*Vector[T], graph map[string]map[string]uint64) AdjacencyConstraint {
+	indexes := make(map[string][]int)
+	for i, vector := range vectors {
+		indexes[vector.Word] = append(indexes[vector.Word], i)
+	}
+	constraint := AdjacencyConstraint{
+		Mode:  Mask,
+		Edges: make(map[int]map[int]float32),
+	}
+	for i, vector := range vectors {
+		for word, count := range graph[vector.Word] {
+			if count == 0 {
+				continue
+			}
+			for _, j := range indexes[word] {
+				constraint.Set(i, j, float32(count))
+			}
+		}
+	}
+	return constraint
+}
+
+// CSRConstraint is a constraint with the edges in a sparse matrix
+type CSRConstraint struct {
+	Mode  ConstraintMode
+	Edges CSR[float32]
+}
+
+// Constrain applies the mode if the edge is in the sparse matrix
+func (c CSRConstraint) Constrain(from, to int, weight float32, linked bool) (float32, bool) {
+	value, found := float32(0), false
+	if from < c.Edges.Rows {
+		value, found = c.Edges.Lookup(from, to)
+	}
+	return c.Mode.constrain(weight, linked, value, found)
+}
+
+// constrainMatrix applies the constraints to every edge of the adjacency matrix, row i and column j is the
+// edge from i to j and the edges that are no longer linked are zeroed
+func constrainMatrix[F Float](adj Matrix[F], constraints []EdgeConstraint) {
+	for i := range adj.Rows {
+		for ii := range adj.Cols {
+			weight, linked := float32(adj.Data[i*adj.Cols+ii]), true
+			for _, constraint := range constraints {
+				weight, linked = constraint.Constrain(i, ii, weight, linked)
+			}
+			if !linked {
+				weight = 0
+			}
+			adj.Data[i*adj.Cols+ii] = F(weight)
+		}
+	}
+}
+
+// constrained is an edge filter that applies the constraints before the filter
+type constrained struct {
+	constraints []EdgeConstraint
+	filter      EdgeFilter
+}
+
+// Filter constrains the edge and filters it if it is still linked
+func (c constrained) Filter(from, to int, weight float32) (float64, bool) {
+	linked := true
+	for _, constraint := range c.constraints {
+		weight, linked = constraint.Constrain(from, to, weight, linked)
+	}
+	if !linked {
+		return 0, false
+	}
+	return c.filter.Filter(from, to, weight)
+}
@@ -0,0 +1,152 @@
+// Copyright 2025 The Morpheus Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestConstraintModes(t *testing.T) {
+	adjacency := AdjacencyConstraint{}
+	adjacency.Set(0, 1, 5)
+	edges := NewCSR[float32](2, [][]int{{1}, {}})
+	edges.Data[0] = 5
+	tests := []struct {
+		mode     ConstraintMode
+		from, to int
+		linked   bool
+		weight   float32
+		ok       bool
+	}{
+		{Mask, 0, 1, true, 2, true},
+		{Mask, 1, 0, true, 2, false},
+		{Mask, 0, 1, false, 2, false},
+		{Force, 0, 1, false, 5, true},
+		{Force, 1, 0, true, 2, true},
+		{Prior, 0, 1, true, 7, true},
+		{Prior, 0, 1, false, 7, false},
+		{Prior, 1, 0, true, 2, true},
+	}
+	for _, test := range tests {
+		adjacency.Mode = test.mode
+		csr := CSRConstraint{Mode: test.mode, Edges: edges}
+		for _, constraint := range []EdgeConstraint{adjacency, csr} {
+			weight, ok := constraint.Constrain(test.from, test.to, 2, test.linked)
+			if weight != test.weight || ok != test.ok {
+				t.Fatalf("%T %+v: %f %t", constraint, test, weight, ok)
+			}
+		}
+	}
+}
+
+func TestConstraints(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	type T struct{}
+	vectors := normal[T](rng, 12, 8)
+	mask, force := AdjacencyConstraint{Mode: Mask}, AdjacencyConstraint{Mode: Force}
+	for i := range vectors {
+		for ii := range vectors {
+			if (i+ii)%3 != 0 {
+				mask.Set(i, ii, 1)
+			}
+		}
+		force.Set(i, 0, 16)
+	}
+	config := Config{
+		Iterations: 8,
+		Size:       8,
+		Divider:    1,
+		Accuracy:   8,
+	}
+	expected := MorpheusGramSchmidt(1, config, vectors, func(cs *Matrix[float32]) {
+		for i := range cs.Rows {
+			for ii := range cs.Cols {
+				if _, ok := mask.Edges[i][ii]; !ok {
+					cs.Data[i*cs.Cols+ii] = 0
+				}
+			}
+			cs.Data[i*cs.Cols] = 16
+		}
+	})
+	config.Constraints = []EdgeConstraint{mask, force}
+	result := MorpheusGramSchmidt(1, config, vectors)
+	for i := range vectors {
+		for ii := range vectors {
+			if expected.Cov[i][ii] != result.Cov[i][ii] {
+				t.Fatalf("cov %d %d: %f != %f", i, ii, expected.Cov[i][ii], result.Cov[i][ii])
+			}
+		}
+	}
+
+	prior := AdjacencyConstraint{Mode: Prior}
+	prior.Set(1, 2, .5)
+	config = Config{
+		Iterations:  8,
+		Size:        8,
+		Divider:     1,
+		Constraints: []EdgeConstraint{mask, prior},
+	}
+	dense := Morpheus(sparseSeed(1), config, vectors)
+	config.Neighbors = len(vectors) - 1
+	sparse := MorpheusSparse(1, config, vectors)
+	for i := range vectors {
+		for ii := range vectors {
+			if math.Abs(dense.Cov[i][ii]-sparse.Sparse.At(i, ii)) > 1e-12 {
+				t.Fatalf("sparse cov %d %d: %f != %f", i, ii, dense.Cov[i][ii], sparse.Sparse.At(i, ii))
+			}
+		}
+	}
+}
+
+func TestMorpheusMarkovConstraints(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	type T struct{}
+	vectors := normal[T](rng, 6, 8)
+	mask, force, prior := AdjacencyConstraint{Mode: Mask}, AdjacencyConstraint{Mode: Force}, AdjacencyConstraint{Mode: Prior}
+	for i := range vectors {
+		for ii := range vectors {
+			if (i+ii)%3 != 0 {
+				mask.Set(i, ii, 1)
+			}
+		}
+	}
+	force.Set(0, 3, 16)
+	prior.Set(1, 4, .5)
+	config := Config{
+		Size:        8,
+		Divider:     1,
+		Markov:      Flow,
+		Constraints: []EdgeConstraint{mask, force, prior},
+	}
+	result := MorpheusMarkov[T, float64](1, config, vectors)
+
+	n := len(vectors)
+	x := NewMatrix(8, n, make([]float64, 8*n)...)
+	for i, vector := range vectors {
+		for ii, value := range vector.Vector {
+			x.Data[i*8+ii] = float64(value)
+		}
+	}
+	adj := x.Unit().MulT(x.Unit())
+	// the constraints weigh the edges in float32
+	for i := range n {
+		for ii := range n {
+			adj.Data[i*n+ii] = float64(float32(adj.Data[i*n+ii]))
+			if _, ok := mask.Edges[i][ii]; !ok {
+				adj.Data[i*n+ii] = 0
+			}
+		}
+	}
+	adj.Data[0*n+3] = 16
+	adj.Data[1*n+4] = float64(float32(adj.Data[1*n+4]) + .5)
+	expected := LearnMarkov(.85, 1024, rand.New(rand.NewSource(1)).Uint32(), adj).Flow()
+	for i, value := range expected.Data {
+		if result.Data[i] != value {
+			t.Fatalf("flow %d: %f != %f", i, result.Data[i], value)
+		}
+	}
+}
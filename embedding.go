@@ -0,0 +1,228 @@
+// Copyright 2025 The Morpheus Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"math/rand"
+)
+
+const (
+	// LanczosTolerance is the largest residual of an eigenpair of an embedding relative to the largest eigenvalue
+	LanczosTolerance = 1e-10
+)
+
+// orthogonalize removes the components of the unit vectors from v twice, which keeps v orthogonal to them
+// in floating point, and returns the length of v
+func orthogonalize(v []float64, vectors [][]float64) float64 {
+	for range 2 {
+		for _, u := range vectors {
+			projection := dot(u, v)
+			for k := range v {
+				v[k] -= projection * u[k]
+			}
+		}
+	}
+	return math.Sqrt(dot(v, v))
+}
+
+// eigen computes the d eigenpairs of an n by n symmetric matrix with the largest eigenvalues with the
+// lanczos algorithm with full reorthogonalization. The krylov subspace grows until the residual of every
+// pair is at most LanczosTolerance times the largest eigenvalue, a subspace that is invariant is continued
+// with a random vector orthogonal to it. There are no eigenpairs if n or d is zero
+func eigen(n, d int, multiply func(y, x []float64)) ([]float64, [][]float64) {
+	if n == 0 || d <= 0 {
+		return nil, nil
+	}
+	rng := rand.New(rand.NewSource(1))
+	random := func(q [][]float64) []float64 {
+		for {
+			v := make([]float64, n)
+			for k := range v {
+				v[k] = rng.NormFloat64()
+			}
+			norm := math.Sqrt(dot(v, v))
+			if length := orthogonalize(v, q); length > 1e-8*norm {
+				for k := range v {
+					v[k] /= length
+				}
+				return v
+			}
+		}
+	}
+	q := [][]float64{random(nil)}
+	alpha, beta := []float64{}, []float64{}
+	scale, check := 0.0, d+8
+	for {
+		m := len(q)
+		w := make([]float64, n)
+		multiply(w, q[m-1])
+		alpha = append(alpha, dot(q[m-1], w))
+		b := orthogonalize(w, q)
+		scale = max(scale, math.Abs(alpha[m-1]), b)
+		if m >= check || m == n {
+			check = m + m/2
+			t := NewMatrix(m, m, make([]float64, m*m)...)
+			for i := range m {
+				t.Data[i*m+i] = alpha[i]
+				if i+1 < m {
+					t.Data[i*m+i+1], t.Data[(i+1)*m+i] = beta[i], beta[i]
+				}
+			}
+			values, rotation := t.Eigen()
+			// the residual of a ritz pair is the coupling to the next vector times the last component of the pair
+			converged, tolerance := m >= d, LanczosTolerance*math.Abs(values[0])
+			for k := range min(d, m) {
+				if b*math.Abs(rotation.Data[k*m+m-1]) > tolerance {
+					converged = false
+					break
+				}
+			}
+			if converged || m == n {
+				d = min(d, m)
+				vectors := make([][]float64, d)
+				for k := range vectors {
+					vectors[k] = make([]float64, n)
+					for j, r := range rotation.Data[k*m : (k+1)*m] {
+						for i := range n {
+							vectors[k][i] += r * q[j][i]
+						}
+					}
+				}
+				return values[:d], vectors
+			}
+		}
+		if b <= 1e-12*scale {
+			// the subspace is invariant so the next vector is not coupled to it
+			beta = append(beta, 0)
+			q = append(q, random(q))
+			continue
+		}
+		beta = append(beta, b)
+		for k := range w {
+			w[k] /= b
+		}
+		q = append(q, w)
+	}
+}
+
+// embedRows embeds the n rows of a symmetric matrix into d dimensions with multiply, which multiplies the
+// matrix with x into y, see Embed. If laplacian is true multiply must multiply with the absolute values
+func embedRows(n, d int, laplacian bool, multiply func(y, x []float64)) [][]float64 {
+	d = max(min(d, n), 0)
+	if laplacian {
+		degree, ones := make([]float64, n), make([]float64, n)
+		for i := range ones {
+			ones[i] = 1
+		}
+		multiply(degree, ones)
+		for i := range degree {
+			if degree[i] > 0 {
+				degree[i] = 1 / math.Sqrt(degree[i])
+			}
+		}
+		// the smallest eigenvectors of I - D^-1/2 W D^-1/2 are the largest eigenvectors of D^-1/2 W D^-1/2
+		w, scaled := multiply, make([]float64, n)
+		multiply = func(y, x []float64) {
+			for i, value := range x {
+				scaled[i] = value * degree[i]
+			}
+			w(y, scaled)
+			for i := range y {
+				y[i] *= degree[i]
+			}
+		}
+	}
+	values, vectors := eigen(n, d, multiply)
+	embedding := make([][]float64, n)
+	for i := range embedding {
+		embedding[i] = make([]float64, d)
+		for ii := range d {
+			embedding[i][ii] = vectors[ii][i]
+		}
+	}
+	if laplacian {
+		for _, row := range embedding {
+			norm := math.Sqrt(dot(row, row))
+			if norm == 0 {
+				continue
+			}
+			for ii := range row {
+				row[ii] /= norm
+			}
+		}
+		return embedding
+	}
+	for ii := range d {
+		scale := math.Sqrt(max(values[ii], 0))
+		for _, row := range embedding {
+			row[ii] *= scale
+		}
+	}
+	return embedding
+}
+
+// Embed embeds the n vectors of an n by n covariance matrix into d dimensions with the top d eigenvectors,
+// the eigenvectors are scaled by the square root of their eigenvalues so distances approximate the covariance.
+// If laplacian is true the eigenvectors are those of the normalized laplacian of the absolute covariance
+// with the smallest eigenvalues and the rows of the embedding are normalized to unit length. Only the top d
+// eigenvectors are computed and the covariance is only multiplied with vectors
+func Embed(cov [][]float64, d int, laplacian bool) [][]float64 {
+	n := len(cov)
+	m := NewMatrix(n, n, make([]float64, n*n)...)
+	for i, row := range cov {
+		copy(m.Data[i*n:(i+1)*n], row)
+	}
+	if laplacian {
+		for i, value := range m.Data {
+			m.Data[i] = math.Abs(value)
+		}
+	}
+	return embedRows(n, d, laplacian, func(y, x []float64) {
+		m.MulT(NewMatrix(n, 1, x...), y)
+	})
+}
+
+// EmbedSparse is Embed for a sparse covariance matrix, which is multiplied with vectors without converting
+// it to a dense matrix. The pattern is made symmetric first, see CSR.Symmetric
+func EmbedSparse(cov CSR[float64], d int, laplacian bool) [][]float64 {
+	cov = cov.Symmetric()
+	if laplacian {
+		cov.Data = append([]float64{}, cov.Data...)
+		for i, value := range cov.Data {
+			cov.Data[i] = math.Abs(value)
+		}
+	}
+	return embedRows(cov.Rows, d, laplacian, func(y, x []float64) {
+		for i := range cov.Rows {
+			indices, data := cov.Row(i)
+			y[i] = 0
+			for k, index := range indices {
+				y[i] += data[k] * x[index]
+			}
+		}
+	})
+}
+
+// spectral adds the embedding of the covariance to the result if config.Embedding is set
+func spectral(config Config, r Result) Result {
+	if config.Embedding <= 0 {
+		return r
+	}
+	if r.Cov == nil {
+		r.Embedding = EmbedSparse(r.Sparse, config.Embedding, config.Laplacian)
+		return r
+	}
+	r.Embedding = Embed(r.Cov, config.Embedding, config.Laplacian)
+	return r
+}
+
+// Points returns the embedding if there is one and the covariance otherwise
+func (r Result) Points() [][]float64 {
+	if r.Embedding != nil {
+		return r.Embedding
+	}
+	return r.Cov
+}
@@ -0,0 +1,172 @@
+// Copyright 2025 The Morpheus Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestEmbed(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	const n, d = 16, 2
+	x := make([][]float64, n)
+	for i := range x {
+		x[i] = []float64{rng.NormFloat64(), rng.NormFloat64()}
+	}
+	cov := make([][]float64, n)
+	for i := range cov {
+		cov[i] = make([]float64, n)
+		for ii := range cov[i] {
+			cov[i][ii] = dot(x[i], x[ii])
+		}
+	}
+	embedding := Embed(cov, d, false)
+	for i := range cov {
+		if len(embedding[i]) != d {
+			t.Fatalf("%d != %d", len(embedding[i]), d)
+		}
+		for ii := range cov[i] {
+			if diff := math.Abs(dot(embedding[i], embedding[ii]) - cov[i][ii]); diff > 1e-9 {
+				t.Fatalf("%d %d: rank %d covariance is off by %g", i, ii, d, diff)
+			}
+		}
+	}
+	for i, row := range Embed(cov, d, true) {
+		if diff := math.Abs(dot(row, row) - 1); diff > 1e-9 {
+			t.Fatalf("laplacian row %d is not unit length: %g", i, diff)
+		}
+	}
+}
+
+func TestMorpheusEmbedding(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	type T struct{}
+	vectors := normal[T](rng, 12, 16)
+	config := Config{
+		Iterations: 8,
+		Size:       16,
+		Divider:    1,
+		Accuracy:   8,
+		Neighbors:  4,
+		Embedding:  3,
+	}
+	for _, result := range []Result{
+		MorpheusGramSchmidt(1, config, vectors),
+		MorpheusSparse(1, config, vectors),
+	} {
+		points := result.Points()
+		if len(points) != len(vectors) || len(points[0]) != config.Embedding {
+			t.Fatalf("embedding is %d by %d", len(points), len(points[0]))
+		}
+	}
+}
+
+// gram is the matrix of the dot products of the rows of the embedding, it doesn't depend on the signs of the eigenvectors
+func gram(embedding [][]float64) [][]float64 {
+	g := make([][]float64, len(embedding))
+	for i := range g {
+		g[i] = make([]float64, len(embedding))
+		for ii := range g[i] {
+			g[i][ii] = dot(embedding[i], embedding[ii])
+		}
+	}
+	return g
+}
+
+func TestEmbedEigen(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	const n, d = 64, 4
+	x := make([][]float64, n)
+	for i := range x {
+		for range n {
+			x[i] = append(x[i], rng.NormFloat64())
+		}
+	}
+	cov := gram(x)
+	m := NewMatrix(n, n, make([]float64, n*n)...)
+	for i := range cov {
+		copy(m.Data[i*n:(i+1)*n], cov[i])
+	}
+	// the reference embedding is the top d eigenvectors of the full jacobi solve
+	values, vectors := m.Eigen()
+	expected := make([][]float64, n)
+	for i := range expected {
+		for ii := range d {
+			expected[i] = append(expected[i], vectors.Data[ii*n+i]*math.Sqrt(values[ii]))
+		}
+	}
+	// the neighbors of the sparse covariance are not symmetric
+	neighbors := make([][]int, n)
+	for i := range neighbors {
+		neighbors[i] = append(neighbors[i], i)
+		for _, j := range rng.Perm(n)[:8] {
+			if j != i {
+				neighbors[i] = append(neighbors[i], j)
+			}
+		}
+	}
+	sparse := NewCSR[float64](n, neighbors)
+	for i := range sparse.Rows {
+		indices, data := sparse.Row(i)
+		for k, j := range indices {
+			data[k] = cov[i][j]
+		}
+	}
+	symmetric := sparse.Symmetric().Dense()
+	for i := range n {
+		for ii := range n {
+			if symmetric.Data[i*n+ii] != symmetric.Data[ii*n+i] {
+				t.Fatalf("%d %d is not symmetric", i, ii)
+			}
+		}
+	}
+	dense := make([][]float64, n)
+	for i := range dense {
+		dense[i] = symmetric.Data[i*n : (i+1)*n]
+	}
+
+	for _, test := range []struct {
+		name                string
+		embedding, expected [][]float64
+		tolerance           float64
+	}{
+		{"dense", Embed(cov, d, false), expected, 1e-6},
+		{"sparse", EmbedSparse(sparse, d, false), Embed(dense, d, false), 1e-6},
+		{"laplacian", EmbedSparse(sparse, d, true), Embed(dense, d, true), 1e-6},
+	} {
+		a, b := gram(test.embedding), gram(test.expected)
+		for i := range a {
+			for ii := range a[i] {
+				if diff := math.Abs(a[i][ii] - b[i][ii]); diff > test.tolerance*math.Max(1, math.Abs(b[i][ii])) {
+					t.Fatalf("%s %d %d: %f != %f", test.name, i, ii, a[i][ii], b[i][ii])
+				}
+			}
+		}
+	}
+}
+
+func TestEmbedEmpty(t *testing.T) {
+	for _, laplacian := range []bool{false, true} {
+		if embedding := Embed(nil, 2, laplacian); len(embedding) != 0 {
+			t.Fatalf("%d rows for no vectors", len(embedding))
+		}
+		if embedding := EmbedSparse(NewCSR[float64](0, nil), 2, laplacian); len(embedding) != 0 {
+			t.Fatalf("%d sparse rows for no vectors", len(embedding))
+		}
+		embedding := Embed([][]float64{{1, 0}, {0, 1}}, 0, laplacian)
+		for i, row := range embedding {
+			if len(row) != 0 {
+				t.Fatalf("row %d has %d dimensions", i, len(row))
+			}
+		}
+	}
+	type T struct{}
+	result := Morpheus(1, Config{Iterations: 1, Size: 16, Embedding: 2}, []*Vector[T]{})
+	if len(result.Embedding) != 0 {
+		t.Fatalf("%d rows for no vectors", len(result.Embedding))
+	}
+}
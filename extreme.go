@@ -76,7 +76,7 @@ func ExtremeMode() {
 		}
 	}
 
-	rng := rand.New(rand.NewSource(1))
+	rng := rand.New(rand.NewSource(*FlagSeed))
 	max, result := float32(0.0), ""
 	for range 64 * 1024 {
 		markov, data := [order]Markov{}, []byte{}
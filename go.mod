@@ -3,9 +3,13 @@ module github.com/pointlander/morpheus
 go 1.25.0
 
 require (
-	github.com/alixaxel/pagerank v0.0.0-20200105181019-900657b89dcb // indirect
+	github.com/alixaxel/pagerank v0.0.0-20200105181019-900657b89dcb
+	github.com/pointlander/gradient v0.0.0-20250814141955-1993bf0b47d3
+	golang.org/x/sys v0.47.0
+)
+
+require (
 	github.com/golang/protobuf v1.4.2 // indirect
-	github.com/pointlander/gradient v0.0.0-20250814141955-1993bf0b47d3 // indirect
 	github.com/ziutek/blas v0.0.0-20190227122918-da4ca23e90bb // indirect
 	google.golang.org/protobuf v1.24.0 // indirect
 )
@@ -0,0 +1,173 @@
+// Copyright 2025 The Morpheus Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+)
+
+// Incremental is a morpheus run that keeps its projections, similarity graphs and ranks so that vectors
+// can be added without starting over. It starts out with the same iterations and ranks as Morpheus for the
+// same seed and config, an adaptive run keeps the number of iterations it converged with. When vectors are
+// added only the new rows and columns of each similarity graph are computed, so the similarity must compare
+// pairs of rows as the kernels do. The default GraphRanker is warm started from the previous ranks and
+// every other ranker ranks the grown graph with the generator of its iteration. The AccumulatingRanker keeps
+// a graph of the original size and can't be grown. A teleport distribution has to be extended to the added
+// vectors in Config.Ranker before they are added
+type Incremental[T any] struct {
+	Config Config
+	// Vectors are the vectors added so far
+	Vectors []*Vector[T]
+	// Constraints rebuilds the edge constraints of the config for all of the vectors when vectors are added,
+	// it is required if the config has constraints
+	Constraints func(vectors []*Vector[T]) []EdgeConstraint
+
+	projection Projection
+	similarity Similarity
+	iterations []*increment
+}
+
+// increment is the state of one iteration of an incremental run
+type increment struct {
+	rng   *rand.Rand
+	a, b  Matrix[float32]
+	x, y  Matrix[float32]
+	cs    Matrix[float32]
+	ranks []float64
+}
+
+// NewIncremental runs Morpheus on the vectors and keeps the state of every iteration
+func NewIncremental[T any](seed int64, config Config, vectors []*Vector[T]) (*Incremental[T], error) {
+	projection, similarity, filter, ranker := config.parts()
+	if err := growable(ranker, len(vectors)); err != nil {
+		return nil, err
+	}
+	m := Incremental[T]{
+		Config:     config,
+		Vectors:    append([]*Vector[T]{}, vectors...),
+		projection: projection,
+		similarity: similarity,
+	}
+	cols, rows, x := encode(config, projection, vectors)
+	var lock sync.Mutex
+	increments := make(map[int]*increment)
+	process := func(iteration int, rng *rand.Rand) []float64 {
+		i := increment{rng: rng}
+		i.a, i.b = projection.Project(rng, cols, rows)
+		i.x, i.y = i.a.MulT(x), i.b.MulT(x)
+		i.cs = similarity.Similarity(i.x, i.y)
+		i.ranks = ranker.Rank(rng, i.graph(), filter)
+		lock.Lock()
+		defer lock.Unlock()
+		increments[iteration] = &i
+		return i.ranks
+	}
+	result := converge(rand.New(rand.NewSource(seed)), config,
+		sequential(projection, similarity, filter, ranker), process, newAccumulator(len(vectors)))
+	m.iterations = make([]*increment, result.Iterations)
+	for i := range m.iterations {
+		m.iterations[i] = increments[i]
+	}
+	return &m, nil
+}
+
+// growable checks that the ranker can rank a graph of n vectors that grows
+func growable(ranker Ranker, n int) error {
+	if _, ok := ranker.(*AccumulatingRanker); ok {
+		return fmt.Errorf("%w: %T can't be grown", ErrRanker, ranker)
+	}
+	if length, ok := teleportLength(ranker); ok && length != n {
+		return fmt.Errorf("%w: teleport has %d values for %d vectors", ErrLength, length, n)
+	}
+	return nil
+}
+
+// graph copies the similarity graph for a ranker, which is free to change it
+func (i *increment) graph() Matrix[float32] {
+	return NewMatrix(i.cs.Cols, i.cs.Rows, append([]float32{}, i.cs.Data...)...)
+}
+
+// grow projects the new input rows and adds their rows and columns to the similarity graph
+func (i *increment) grow(similarity Similarity, input Matrix[float32]) {
+	previous := i.x.Rows
+	x, y := i.a.MulT(input), i.b.MulT(input)
+	i.x.Data, i.x.Rows = append(i.x.Data, x.Data...), i.x.Rows+x.Rows
+	i.y.Data, i.y.Rows = append(i.y.Data, y.Data...), i.y.Rows+y.Rows
+	n := i.x.Rows
+	cs := NewMatrix(n, n, make([]float32, n*n)...)
+	if previous > 0 {
+		for row := range previous {
+			copy(cs.Data[row*n:row*n+previous], i.cs.Data[row*previous:(row+1)*previous])
+		}
+		old := NewMatrix(i.x.Cols, previous, i.x.Data[:previous*i.x.Cols]...)
+		right := similarity.Similarity(old, y)
+		for row := range previous {
+			copy(cs.Data[row*n+previous:(row+1)*n], right.Data[row*right.Cols:(row+1)*right.Cols])
+		}
+	}
+	bottom := similarity.Similarity(x, i.y)
+	copy(cs.Data[previous*n:], bottom.Data)
+	i.cs = cs
+}
+
+// Add projects the new vectors, adds them to the graphs, ranks the grown graphs and returns the updated result
+func (m *Incremental[T]) Add(vectors ...*Vector[T]) (Result, error) {
+	if len(vectors) == 0 {
+		return m.Result(), nil
+	}
+	if err := validateVectors(m.Config, vectors); err != nil {
+		return Result{}, err
+	}
+	n, previous := len(m.Vectors)+len(vectors), len(m.Vectors)
+	all := append(m.Vectors[:previous:previous], vectors...)
+	config := m.Config
+	if len(config.Constraints) > 0 {
+		if m.Constraints == nil {
+			return Result{}, fmt.Errorf("%w: %d vectors are added without rebuilding the constraints", ErrConstraint, len(vectors))
+		}
+		config.Constraints = m.Constraints(all)
+	}
+	_, _, filter, ranker := config.parts()
+	if err := growable(ranker, n); err != nil {
+		return Result{}, err
+	}
+	m.Config, m.Vectors = config, all
+	_, _, input := encode(m.Config, m.projection, vectors)
+	errs := make([]error, len(m.iterations))
+	iterate(len(m.iterations), sequential(m.similarity, filter, ranker), func(iteration int) {
+		i := m.iterations[iteration]
+		i.grow(m.similarity, input)
+		graphRanker, ok := ranker.(GraphRanker)
+		if !ok {
+			i.ranks = ranker.Rank(i.rng, i.graph(), filter)
+			return
+		}
+		warm := make([]float64, n)
+		for ii, value := range i.ranks {
+			warm[ii] = value * float64(previous) / float64(n)
+		}
+		for ii := previous; ii < n; ii++ {
+			warm[ii] = 1 / float64(n)
+		}
+		i.ranks, errs[iteration] = graphRanker.pagerank(link(i.cs, filter), warm)
+	})
+	for _, err := range errs {
+		if err != nil {
+			return Result{}, err
+		}
+	}
+	return m.Result(), nil
+}
+
+// Result computes the statistics of the current ranks
+func (m *Incremental[T]) Result() Result {
+	ranks := make([][]float64, len(m.iterations))
+	for i, increment := range m.iterations {
+		ranks[i] = increment.ranks
+	}
+	return spectral(m.Config, statistics(ranks, len(m.Vectors)))
+}
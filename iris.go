@@ -96,7 +96,7 @@ func Load() []Fisher {
 
 // IrisMode is the iris clustering mode
 func IrisMode() {
-	rng := rand.New(rand.NewSource(1))
+	rng := rand.New(rand.NewSource(*FlagSeed))
 	vectors := make([]*Vector[Fisher], 150)
 	{
 		iris := Load()
@@ -113,13 +113,22 @@ func IrisMode() {
 		Iterations: 512,
 		Size:       4,
 		Divider:    1,
+		Similarity: Kernels[*FlagKernel],
+		Ranker:     Rankers[*FlagRanker],
+		Embedding:  *FlagEmbedding,
+		Laplacian:  *FlagLaplacian,
 	}
-	cov := MorpheusGramSchmidt(rng.Int63(), config, vectors)
+	result := MorpheusGramSchmidt(rng.Int63(), config, vectors)
+	cov := result.Cov
 
-	sort.Slice(vectors, func(i, j int) bool {
-		return vectors[i].Stddev < vectors[j].Stddev
+	indexes := make([]int, len(vectors))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.Slice(indexes, func(i, j int) bool {
+		return result.Stddev[indexes[i]] < result.Stddev[indexes[j]]
 	})
-	for i := range vectors {
+	for _, i := range indexes {
 		fmt.Println(vectors[i].Meta.Label)
 	}
 
@@ -128,38 +137,18 @@ func IrisMode() {
 		fmt.Println(cov[i])
 	}
 	fmt.Println("u=")
-	for i := range vectors {
-		fmt.Printf("%v ", vectors[i].Avg)
+	for _, i := range indexes {
+		fmt.Printf("%v ", result.Avg[i])
 	}
 	fmt.Println()
 
-	sort.Slice(vectors, func(i, j int) bool {
-		return vectors[i].Meta.Index < vectors[j].Meta.Index
-	})
-
-	meta := make([][]float64, len(vectors))
-	for i := range meta {
-		meta[i] = make([]float64, len(vectors))
-	}
 	const k = 3
-	for i := 0; i < 33; i++ {
-		clusters, _, err := kmeans.Kmeans(int64(i+1), cov, k, kmeans.SquaredEuclideanDistance, -1)
-		if err != nil {
-			panic(err)
-		}
-		for i := 0; i < len(meta); i++ {
-			target := clusters[i]
-			for j, v := range clusters {
-				if v == target {
-					meta[i][j]++
-				}
-			}
-		}
-	}
-	clusters, _, err := kmeans.Kmeans(1, meta, 3, kmeans.SquaredEuclideanDistance, -1)
+	points := result.Points()
+	consensus, err := kmeans.Consensus(rng.Int63(), points, k, 33, kmeans.ConsensusOptions{})
 	if err != nil {
 		panic(err)
 	}
+	clusters := consensus.Labels
 	for i, value := range clusters {
 		vectors[i].Meta.Cluster = value
 	}
@@ -185,7 +174,7 @@ func IrisMode() {
 
 	var auto [3]*AutoEncoder
 	for i := range auto {
-		auto[i] = NewAutoEncoder(len(vectors), 1)
+		auto[i] = NewAutoEncoder(len(vectors), rng.Int63())
 	}
 	for i := range cov {
 		sum := 0.0
@@ -225,7 +214,7 @@ func IrisMode() {
 
 // IrisMarkovMode is the iris markov clustering mode
 func IrisMarkovMode() {
-	rng := rand.New(rand.NewSource(1))
+	rng := rand.New(rand.NewSource(*FlagSeed))
 	vectors := make([]*Vector[Fisher], 150)
 	{
 		iris := Load()
@@ -256,29 +245,12 @@ func IrisMarkovMode() {
 		return vectors[i].Meta.Index < vectors[j].Meta.Index
 	})
 
-	meta := make([][]float64, len(vectors))
-	for i := range meta {
-		meta[i] = make([]float64, len(vectors))
-	}
 	const k = 3
-	for i := 0; i < 33; i++ {
-		clusters, _, err := kmeans.Kmeans(int64(i+1), cov, k, kmeans.SquaredEuclideanDistance, -1)
-		if err != nil {
-			panic(err)
-		}
-		for i := 0; i < len(meta); i++ {
-			target := clusters[i]
-			for j, v := range clusters {
-				if v == target {
-					meta[i][j]++
-				}
-			}
-		}
-	}
-	clusters, _, err := kmeans.Kmeans(1, meta, 3, kmeans.SquaredEuclideanDistance, -1)
+	consensus, err := kmeans.Consensus(rng.Int63(), cov, k, 33, kmeans.ConsensusOptions{})
 	if err != nil {
 		panic(err)
 	}
+	clusters := consensus.Labels
 	for i, value := range clusters {
 		vectors[i].Meta.Cluster = value
 	}
@@ -304,7 +276,7 @@ func IrisMarkovMode() {
 
 	var auto [3]*AutoEncoder
 	for i := range auto {
-		auto[i] = NewAutoEncoder(len(vectors), 1)
+		auto[i] = NewAutoEncoder(len(vectors), rng.Int63())
 	}
 	for i := range cov {
 		sum := 0.0
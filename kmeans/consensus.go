@@ -0,0 +1,167 @@
+package kmeans
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/pointlander/morpheus/parallel"
+)
+
+// ConsensusStep is the final step of the consensus clustering
+type ConsensusStep int
+
+const (
+	// ConsensusKmeans clusters the rows of the co-association matrix with k-means
+	ConsensusKmeans ConsensusStep = iota
+	// ConsensusAverageLinkage merges the clusters with the largest average co-association
+	// until there are k clusters
+	ConsensusAverageLinkage
+)
+
+// ConsensusOptions are the options of the consensus clustering
+type ConsensusOptions struct {
+	// Options are the options of the runs and of the final k-means
+	Options
+	// Step is the final step
+	Step ConsensusStep
+}
+
+// ConsensusResult is the result of the consensus clustering
+type ConsensusResult struct {
+	// Labels are the clusters of the observations
+	Labels []int
+	// CoAssociation is the fraction of the runs that put observation i and j in the same cluster
+	CoAssociation [][]float64
+}
+
+// Consensus clusters the data with runs k-means runs in parallel, the seeds of the runs and
+// of the final k-means are drawn from rngSeed. The observations are then clustered by the
+// fraction of the runs that put them in the same cluster
+func Consensus(rngSeed int64, rawData [][]float64, k, runs int, options ConsensusOptions) (ConsensusResult, error) {
+	rng := rand.New(rand.NewSource(rngSeed))
+	seeds := make([]int64, runs)
+	for i := range seeds {
+		seeds[i] = rng.Int63()
+	}
+	labels, errs := make([][]int, runs), make([]error, runs)
+	parallel.For(runs, func(run int) {
+		result, err := Cluster(seeds[run], rawData, k, options.Options)
+		labels[run], errs[run] = result.Labels, err
+	})
+	for _, err := range errs {
+		if err != nil {
+			return ConsensusResult{}, err
+		}
+	}
+
+	n := len(rawData)
+	result := ConsensusResult{
+		CoAssociation: make([][]float64, n),
+	}
+	for i := range result.CoAssociation {
+		result.CoAssociation[i] = make([]float64, n)
+	}
+	for _, clusters := range labels {
+		for i, target := range clusters {
+			for j, v := range clusters {
+				if v == target {
+					result.CoAssociation[i][j] += 1 / float64(runs)
+				}
+			}
+		}
+	}
+
+	switch options.Step {
+	case ConsensusAverageLinkage:
+		result.Labels = averageLinkage(result.CoAssociation, k)
+	default:
+		clustering, err := Cluster(rng.Int63(), result.CoAssociation, k, options.Options)
+		if err != nil {
+			return ConsensusResult{}, err
+		}
+		result.Labels = clustering.Labels
+	}
+	return result, nil
+}
+
+// merge is a merge of two clusters of the average linkage
+type merge struct {
+	a, b       int
+	similarity float64
+}
+
+// averageLinkage clusters by similarity with the nearest neighbor chain algorithm, which finds
+// all the merges of the average linkage and then applies the n-k merges of largest similarity
+func averageLinkage(similarity [][]float64, k int) []int {
+	n := len(similarity)
+	s := make([][]float64, n)
+	for i := range s {
+		s[i] = append([]float64{}, similarity[i]...)
+	}
+	size, active := make([]int, n), make([]bool, n)
+	for i := range size {
+		size[i], active[i] = 1, true
+	}
+	merges, chain := make([]merge, 0, n), make([]int, 0, n)
+	for len(merges) < n-1 {
+		if len(chain) == 0 {
+			for i := range active {
+				if active[i] {
+					chain = append(chain, i)
+					break
+				}
+			}
+		}
+		a, b := chain[len(chain)-1], -1
+		if len(chain) > 1 {
+			b = chain[len(chain)-2]
+		}
+		// the previous cluster of the chain wins ties so that the chain ends
+		for c := range active {
+			if active[c] && c != a && (b == -1 || s[a][c] > s[a][b]) {
+				b = c
+			}
+		}
+		if len(chain) < 2 || b != chain[len(chain)-2] {
+			chain = append(chain, b)
+			continue
+		}
+		chain = chain[:len(chain)-2]
+		merges = append(merges, merge{a: a, b: b, similarity: s[a][b]})
+		for c := range active {
+			if active[c] {
+				s[a][c] = (float64(size[a])*s[a][c] + float64(size[b])*s[b][c]) / float64(size[a]+size[b])
+				s[c][a] = s[a][c]
+			}
+		}
+		size[a] += size[b]
+		active[b] = false
+	}
+
+	sort.SliceStable(merges, func(i, j int) bool {
+		return merges[i].similarity > merges[j].similarity
+	})
+	parent := make([]int, n)
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for _, m := range merges[:min(len(merges), max(0, n-k))] {
+		parent[find(m.b)] = find(m.a)
+	}
+	labels, clusters := make([]int, n), make(map[int]int)
+	for i := range labels {
+		root := find(i)
+		if _, ok := clusters[root]; !ok {
+			clusters[root] = len(clusters)
+		}
+		labels[i] = clusters[root]
+	}
+	return labels
+}
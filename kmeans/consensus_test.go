@@ -0,0 +1,74 @@
+package kmeans
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestConsensus(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	data, labels := blobs(rng, 3, 30)
+	for _, step := range []ConsensusStep{ConsensusKmeans, ConsensusAverageLinkage} {
+		result, err := Consensus(1, data, 3, 16, ConsensusOptions{Step: step})
+		if err != nil {
+			t.Fatal(err)
+		}
+		clusters := make(map[int]int)
+		for i, label := range result.Labels {
+			if cluster, ok := clusters[labels[i]]; ok && cluster != label {
+				t.Fatalf("step %d %d: blob %d is split", step, i, labels[i])
+			}
+			clusters[labels[i]] = label
+		}
+		if len(clusters) != 3 {
+			t.Fatalf("step %d: %d clusters", step, len(clusters))
+		}
+		for i := range result.CoAssociation {
+			if math.Abs(result.CoAssociation[i][i]-1) > 1e-9 {
+				t.Fatalf("%d is not always with itself", i)
+			}
+			for j, value := range result.CoAssociation[i] {
+				if value < 0 || value > 1+1e-9 || value != result.CoAssociation[j][i] {
+					t.Fatalf("%d %d: %f", i, j, value)
+				}
+			}
+		}
+
+		again, _ := Consensus(1, data, 3, 16, ConsensusOptions{Step: step})
+		for i := range again.Labels {
+			if again.Labels[i] != result.Labels[i] {
+				t.Fatalf("step %d is not deterministic", step)
+			}
+		}
+	}
+}
+
+func TestAverageLinkage(t *testing.T) {
+	// 0 and 1 are close, 2 is closer to them on average than to 3 and 4
+	similarity := [][]float64{
+		{1, .9, .5, .1, 0},
+		{.9, 1, .3, 0, .1},
+		{.5, .3, 1, .2, .2},
+		{.1, 0, .2, 1, .8},
+		{0, .1, .2, .8, 1},
+	}
+	tests := []struct {
+		k      int
+		labels []int
+	}{
+		{1, []int{0, 0, 0, 0, 0}},
+		{2, []int{0, 0, 0, 1, 1}},
+		{3, []int{0, 0, 1, 2, 2}},
+		{4, []int{0, 0, 1, 2, 3}},
+		{5, []int{0, 1, 2, 3, 4}},
+	}
+	for _, test := range tests {
+		labels := averageLinkage(similarity, test.k)
+		for i := range labels {
+			if labels[i] != test.labels[i] {
+				t.Fatalf("k=%d: %v != %v", test.k, labels, test.labels)
+			}
+		}
+	}
+}
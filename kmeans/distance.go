@@ -16,9 +16,21 @@ implemented separately.
 */
 
 import (
+	"errors"
 	"math"
 )
 
+// ErrLength is the error of a distance between vectors of different lengths
+var ErrLength = errors.New("kmeans: the vectors have different lengths")
+
+// length checks that the vectors have the same length
+func length(firstVector, secondVector []float64) error {
+	if len(firstVector) != len(secondVector) {
+		return ErrLength
+	}
+	return nil
+}
+
 // Lp Norm of an array, given p >= 1
 func LPNorm(vector []float64, p float64) (float64, error) {
 	distance := 0.
@@ -30,6 +42,9 @@ func LPNorm(vector []float64, p float64) (float64, error) {
 
 // 1-norm distance (l_1 distance)
 func ManhattanDistance(firstVector, secondVector []float64) (float64, error) {
+	if err := length(firstVector, secondVector); err != nil {
+		return 0, err
+	}
 	distance := 0.
 	for ii := range firstVector {
 		distance += math.Abs(firstVector[ii] - secondVector[ii])
@@ -39,6 +54,9 @@ func ManhattanDistance(firstVector, secondVector []float64) (float64, error) {
 
 // 2-norm distance (l_2 distance)
 func EuclideanDistance(firstVector, secondVector []float64) (float64, error) {
+	if err := length(firstVector, secondVector); err != nil {
+		return 0, err
+	}
 	distance := 0.
 	for ii := range firstVector {
 		distance += (firstVector[ii] - secondVector[ii]) * (firstVector[ii] - secondVector[ii])
@@ -55,6 +73,9 @@ func SquaredEuclideanDistance(firstVector, secondVector []float64) (float64, err
 
 // p-norm distance (l_p distance)
 func MinkowskiDistance(firstVector, secondVector []float64, p float64) (float64, error) {
+	if err := length(firstVector, secondVector); err != nil {
+		return 0, err
+	}
 	distance := 0.
 	for ii := range firstVector {
 		distance += math.Pow(math.Abs(firstVector[ii]-secondVector[ii]), p)
@@ -64,6 +85,12 @@ func MinkowskiDistance(firstVector, secondVector []float64, p float64) (float64,
 
 // p-norm distance with weights (weighted l_p distance)
 func WeightedMinkowskiDistance(firstVector, secondVector, weightVector []float64, p float64) (float64, error) {
+	if err := length(firstVector, secondVector); err != nil {
+		return 0, err
+	}
+	if err := length(firstVector, weightVector); err != nil {
+		return 0, err
+	}
 	distance := 0.
 	for ii := range firstVector {
 		distance += weightVector[ii] * math.Pow(math.Abs(firstVector[ii]-secondVector[ii]), p)
@@ -73,6 +100,9 @@ func WeightedMinkowskiDistance(firstVector, secondVector, weightVector []float64
 
 // infinity norm distance (l_inf distance)
 func ChebyshevDistance(firstVector, secondVector []float64) (float64, error) {
+	if err := length(firstVector, secondVector); err != nil {
+		return 0, err
+	}
 	distance := 0.
 	for ii := range firstVector {
 		if math.Abs(firstVector[ii]-secondVector[ii]) >= distance {
@@ -83,6 +113,9 @@ func ChebyshevDistance(firstVector, secondVector []float64) (float64, error) {
 }
 
 func HammingDistance(firstVector, secondVector []float64) (float64, error) {
+	if err := length(firstVector, secondVector); err != nil {
+		return 0, err
+	}
 	distance := 0.
 	for ii := range firstVector {
 		if firstVector[ii] != secondVector[ii] {
@@ -93,6 +126,9 @@ func HammingDistance(firstVector, secondVector []float64) (float64, error) {
 }
 
 func BrayCurtisDistance(firstVector, secondVector []float64) (float64, error) {
+	if err := length(firstVector, secondVector); err != nil {
+		return 0, err
+	}
 	numerator, denominator := 0., 0.
 	for ii := range firstVector {
 		numerator += math.Abs(firstVector[ii] - secondVector[ii])
@@ -102,6 +138,9 @@ func BrayCurtisDistance(firstVector, secondVector []float64) (float64, error) {
 }
 
 func CanberraDistance(firstVector, secondVector []float64) (float64, error) {
+	if err := length(firstVector, secondVector); err != nil {
+		return 0, err
+	}
 	distance := 0.
 	for ii := range firstVector {
 		distance += (math.Abs(firstVector[ii]-secondVector[ii]) / (math.Abs(firstVector[ii]) + math.Abs(secondVector[ii])))
@@ -3,6 +3,8 @@ package kmeans
 import (
 	"math"
 	"math/rand"
+
+	"github.com/pointlander/morpheus/parallel"
 )
 
 // Observation: Data Abstraction for an N-dimensional
@@ -57,29 +59,37 @@ func (observation Observation) OuterProduct(otherObservation Observation) [][]fl
 
 // Find the closest observation and return the distance
 // Index of observation, distance
-func near(p ClusteredObservation, mean []Observation, distanceFunction DistanceFunction) (int, float64) {
+func near(p ClusteredObservation, mean []Observation, distanceFunction DistanceFunction) (int, float64, error) {
 	indexOfCluster := 0
-	minSquaredDistance, _ := distanceFunction(p.Observation, mean[0])
+	minSquaredDistance, err := distanceFunction(p.Observation, mean[0])
+	if err != nil {
+		return 0, 0, err
+	}
 	for i := 1; i < len(mean); i++ {
-		squaredDistance, _ := distanceFunction(p.Observation, mean[i])
+		squaredDistance, err := distanceFunction(p.Observation, mean[i])
+		if err != nil {
+			return 0, 0, err
+		}
 		if squaredDistance < minSquaredDistance {
 			minSquaredDistance = squaredDistance
 			indexOfCluster = i
 		}
 	}
-	return indexOfCluster, math.Sqrt(minSquaredDistance)
+	return indexOfCluster, math.Sqrt(minSquaredDistance), nil
 }
 
 // Instead of initializing randomly the seeds, make a sound decision of initializing
-func seed(rng *rand.Rand, data []ClusteredObservation, k int, distanceFunction DistanceFunction) []Observation {
+func seed(rng *rand.Rand, data []ClusteredObservation, k int, distanceFunction DistanceFunction) ([]Observation, error) {
 	s := make([]Observation, k)
 	first := rng.Intn(len(data))
 	s[0] = data[first].Observation
 	d2 := make([]float64, len(data))
 	for ii := 1; ii < k; ii++ {
+		if _, err := assign(data, s[:ii], distanceFunction, d2); err != nil {
+			return nil, err
+		}
 		var sum float64
-		for jj, p := range data {
-			_, dMin := near(p, s[:ii], distanceFunction)
+		for jj, dMin := range d2 {
 			d2[jj] = dMin * dMin
 			sum += d2[jj]
 		}
@@ -90,57 +100,270 @@ func seed(rng *rand.Rand, data []ClusteredObservation, k int, distanceFunction D
 		}
 		s[ii] = data[jj].Observation
 	}
-	return s
+	return s, nil
+}
+
+const (
+	// AssignBlock is the number of observations in a block of the assignment step
+	AssignBlock = 256
+	// AssignParallel is the number of observations times clusters times dimensions
+	// above which the blocks of the assignment step are processed in parallel
+	AssignParallel = 1 << 18
+)
+
+// assign moves each observation into the cluster of the closest mean and stores the distance to it,
+// it returns the number of observations that changed their cluster. Each block of observations is
+// assigned on its own, so the result doesn't depend on the number of cpus
+func assign(data []ClusteredObservation, mean []Observation, distanceFunction DistanceFunction, distances []float64) (int, error) {
+	if len(data) == 0 {
+		return 0, nil
+	}
+	blocks := (len(data) + AssignBlock - 1) / AssignBlock
+	changes, errs := make([]int, blocks), make([]error, blocks)
+	process := func(block int) {
+		for ii := block * AssignBlock; ii < min(len(data), (block+1)*AssignBlock); ii++ {
+			closestCluster, distance, err := near(data[ii], mean, distanceFunction)
+			if err != nil {
+				errs[block] = err
+				return
+			}
+			if closestCluster != data[ii].ClusterNumber {
+				changes[block]++
+				data[ii].ClusterNumber = closestCluster
+			}
+			distances[ii] = distance
+		}
+	}
+	if blocks == 1 || len(data)*len(mean)*len(data[0].Observation) < AssignParallel {
+		for block := range blocks {
+			process(block)
+		}
+	} else {
+		parallel.For(blocks, process)
+	}
+
+	total := 0
+	for block, err := range errs {
+		if err != nil {
+			return 0, err
+		}
+		total += changes[block]
+	}
+	return total, nil
+}
+
+const (
+	// MaxIterations is the default maximum number of Lloyd iterations
+	MaxIterations = 300
+)
+
+// Options are the options of the k-means clustering
+type Options struct {
+	// MaxIterations is the maximum number of Lloyd iterations of each run, it defaults to MaxIterations
+	MaxIterations int
+	// Tolerance stops a run when no centroid moves further than Tolerance, a run always stops
+	// when no observation changes its cluster
+	Tolerance float64
+	// Restarts is the number of runs from different k-means++ seeds, the run with the smallest
+	// inertia is returned, it defaults to 1
+	Restarts int
+	// Distance is the distance function, it defaults to SquaredEuclideanDistance
+	Distance DistanceFunction
+	// BatchSize is the number of observations in a batch of mini-batch k-means, the iterations are
+	// batches and the full data is clustered with Lloyd iterations when it is zero
+	BatchSize int
+}
+
+// Result is the result of the k-means clustering
+type Result struct {
+	// Labels are the clusters of the observations
+	Labels []int
+	// Centroids are the means of the clusters
+	Centroids []Observation
+	// Inertia is the sum of the distances of the observations to their centroids
+	Inertia float64
+	// Iterations is the number of Lloyd iterations of the returned run
+	Iterations int
+}
+
+// shift is the euclidean distance a centroid moved
+func shift(from, to Observation) float64 {
+	distance, _ := EuclideanDistance(from, to)
+	return distance
+}
+
+// reseed moves the observation that is farthest from its mean into each empty cluster,
+// the observation is only taken from a cluster that has other observations
+func reseed(data []ClusteredObservation, distances []float64, mLen []int) {
+	for ii := range mLen {
+		if mLen[ii] > 0 {
+			continue
+		}
+		farthest := -1
+		for jj, p := range data {
+			if mLen[p.ClusterNumber] > 1 && (farthest == -1 || distances[jj] > distances[farthest]) {
+				farthest = jj
+			}
+		}
+		if farthest == -1 {
+			return
+		}
+		mLen[data[farthest].ClusterNumber]--
+		data[farthest].ClusterNumber = ii
+		distances[farthest] = 0
+		mLen[ii]++
+	}
+}
+
+// settle re-seeds the clusters that the last assignment left empty and moves the means of the clusters
+// that gained or lost an observation to the means of their observations, so a result doesn't have an
+// empty cluster with a stale mean when there are enough observations
+func settle(data []ClusteredObservation, mean []Observation, distances []float64) {
+	mLen := make([]int, len(mean))
+	for _, p := range data {
+		mLen[p.ClusterNumber]++
+	}
+	labels := make([]int, len(data))
+	for ii, p := range data {
+		labels[ii] = p.ClusterNumber
+	}
+	reseed(data, distances, mLen)
+	changed := make([]bool, len(mean))
+	for ii, p := range data {
+		if p.ClusterNumber != labels[ii] {
+			changed[p.ClusterNumber], changed[labels[ii]] = true, true
+		}
+	}
+	for ii := range mean {
+		if changed[ii] {
+			mean[ii] = make(Observation, len(mean[ii]))
+		}
+	}
+	for _, p := range data {
+		if changed[p.ClusterNumber] {
+			mean[p.ClusterNumber].Add(p.Observation)
+		}
+	}
+	for ii := range mean {
+		if changed[ii] {
+			mean[ii].Mul(1 / float64(mLen[ii]))
+		}
+	}
 }
 
-// K-Means Algorithm
-func kmeans(data []ClusteredObservation, mean []Observation, distanceFunction DistanceFunction, threshold int) ([]ClusteredObservation, error) {
-	counter := 0
-	for ii, jj := range data {
-		closestCluster, _ := near(jj, mean, distanceFunction)
-		data[ii].ClusterNumber = closestCluster
+// K-Means Algorithm, the means are updated until the labels don't change, no mean moves further
+// than the tolerance or the maximum number of iterations is reached. An empty cluster is re-seeded
+// with the observation that is farthest from its mean, also after the last assignment
+func kmeans(data []ClusteredObservation, mean []Observation, options Options) (Result, error) {
+	distances := make([]float64, len(data))
+	if _, err := assign(data, mean, options.Distance, distances); err != nil {
+		return Result{}, err
 	}
 	mLen := make([]int, len(mean))
-	for n := len(data[0].Observation); ; {
-		for ii := range mean {
-			mean[ii] = make(Observation, n)
+	iterations := 0
+	for n := len(data[0].Observation); iterations < options.MaxIterations; {
+		for ii := range mLen {
 			mLen[ii] = 0
 		}
 		for _, p := range data {
-			mean[p.ClusterNumber].Add(p.Observation)
 			mLen[p.ClusterNumber]++
 		}
-		for ii := range mean {
-			mean[ii].Mul(1 / float64(mLen[ii]))
+		reseed(data, distances, mLen)
+		next := make([]Observation, len(mean))
+		for ii := range next {
+			next[ii] = make(Observation, n)
 		}
-		var changes int
-		for ii, p := range data {
-			if closestCluster, _ := near(p, mean, distanceFunction); closestCluster != p.ClusterNumber {
-				changes++
-				data[ii].ClusterNumber = closestCluster
+		for _, p := range data {
+			next[p.ClusterNumber].Add(p.Observation)
+		}
+		moved := 0.0
+		for ii := range next {
+			if mLen[ii] == 0 {
+				next[ii] = append(next[ii][:0], mean[ii]...)
+				continue
 			}
+			next[ii].Mul(1 / float64(mLen[ii]))
+			moved = math.Max(moved, shift(mean[ii], next[ii]))
+		}
+		mean = next
+		changes, err := assign(data, mean, options.Distance, distances)
+		if err != nil {
+			return Result{}, err
 		}
-		counter++
-		if changes == 0 || counter > threshold {
-			return data, nil
+		iterations++
+		if changes == 0 || moved <= options.Tolerance {
+			break
 		}
 	}
-	return data, nil
+	settle(data, mean, distances)
+
+	return label(data, mean, iterations, options.Distance)
 }
 
-// K-Means Algorithm with smart seeds
-// as known as K-Means ++
-func Kmeans(rngSeed int64, rawData [][]float64, k int, distanceFunction DistanceFunction, threshold int) ([]int, []Observation, error) {
+// label is the result of the observations assigned to the means
+func label(data []ClusteredObservation, mean []Observation, iterations int, distanceFunction DistanceFunction) (Result, error) {
+	result := Result{
+		Labels:     make([]int, len(data)),
+		Centroids:  mean,
+		Iterations: iterations,
+	}
+	for ii, p := range data {
+		result.Labels[ii] = p.ClusterNumber
+		distance, err := distanceFunction(p.Observation, mean[p.ClusterNumber])
+		if err != nil {
+			return Result{}, err
+		}
+		result.Inertia += distance
+	}
+	return result, nil
+}
+
+// Cluster clusters the data into k clusters with k-means++ seeds, the restarts draw their
+// seeds and batches one after the other from rngSeed
+func Cluster(rngSeed int64, rawData [][]float64, k int, options Options) (Result, error) {
+	if options.MaxIterations <= 0 {
+		options.MaxIterations = MaxIterations
+	}
+	if options.Restarts <= 0 {
+		options.Restarts = 1
+	}
+	if options.Distance == nil {
+		options.Distance = SquaredEuclideanDistance
+	}
 	rng := rand.New(rand.NewSource(rngSeed))
 	data := make([]ClusteredObservation, len(rawData))
 	for ii, jj := range rawData {
 		data[ii].Observation = jj
 	}
-	seeds := seed(rng, data, k, distanceFunction)
-	clusteredData, err := kmeans(data, seeds, distanceFunction, threshold)
-	labels := make([]int, len(clusteredData))
-	for ii, jj := range clusteredData {
-		labels[ii] = jj.ClusterNumber
+	var best Result
+	for restart := range options.Restarts {
+		seeds, err := seed(rng, data, k, options.Distance)
+		if err != nil {
+			return Result{}, err
+		}
+		var result Result
+		if options.BatchSize > 0 {
+			result, err = miniBatch(rng, data, seeds, options)
+		} else {
+			result, err = kmeans(data, seeds, options)
+		}
+		if err != nil {
+			return Result{}, err
+		}
+		if restart == 0 || result.Inertia < best.Inertia {
+			best = result
+		}
 	}
-	return labels, seeds, err
+	return best, nil
+}
+
+// K-Means Algorithm with smart seeds
+// as known as K-Means ++, the threshold is the maximum number of iterations
+// and a threshold below one is the default of Cluster
+func Kmeans(rngSeed int64, rawData [][]float64, k int, distanceFunction DistanceFunction, threshold int) ([]int, []Observation, error) {
+	result, err := Cluster(rngSeed, rawData, k, Options{
+		MaxIterations: threshold,
+		Distance:      distanceFunction,
+	})
+	return result.Labels, result.Centroids, err
 }
@@ -0,0 +1,166 @@
+package kmeans
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// blobs draws points around k centers that are far apart
+func blobs(rng *rand.Rand, k, points int) ([][]float64, []int) {
+	data, labels := make([][]float64, 0, k*points), make([]int, 0, k*points)
+	for i := range k * points {
+		center := float64(i%k) * 10
+		data = append(data, []float64{center + rng.NormFloat64(), -center + rng.NormFloat64()})
+		labels = append(labels, i%k)
+	}
+	return data, labels
+}
+
+func TestCluster(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	data, labels := blobs(rng, 3, 50)
+	result, err := Cluster(1, data, 3, Options{Restarts: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Iterations < 1 || result.Iterations >= MaxIterations {
+		t.Fatalf("%d iterations", result.Iterations)
+	}
+	// the clusters are the blobs and the centroids are the means of the clusters
+	clusters, inertia := make(map[int]int), 0.0
+	for i, label := range result.Labels {
+		if cluster, ok := clusters[labels[i]]; ok && cluster != label {
+			t.Fatalf("%d: blob %d is split", i, labels[i])
+		}
+		clusters[labels[i]] = label
+		distance, _ := SquaredEuclideanDistance(data[i], result.Centroids[label])
+		inertia += distance
+	}
+	if len(clusters) != 3 {
+		t.Fatalf("%d clusters", len(clusters))
+	}
+	for blob, cluster := range clusters {
+		center := float64(blob) * 10
+		if centroid := result.Centroids[cluster]; math.Abs(centroid[0]-center) > 1 || math.Abs(centroid[1]+center) > 1 {
+			t.Fatalf("centroid %v is not near %f", centroid, center)
+		}
+	}
+	if math.Abs(inertia-result.Inertia) > 1e-9 {
+		t.Fatalf("inertia %f != %f", result.Inertia, inertia)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	data := make([][]float64, 200)
+	for i := range data {
+		data[i] = []float64{rng.Float64(), rng.Float64()}
+	}
+	single, _ := Cluster(2, data, 8, Options{})
+	restarts, _ := Cluster(2, data, 8, Options{Restarts: 8})
+	if restarts.Inertia > single.Inertia {
+		t.Fatalf("the restarts made the inertia worse: %f > %f", restarts.Inertia, single.Inertia)
+	}
+	if limited, _ := Cluster(2, data, 8, Options{MaxIterations: 2}); limited.Iterations != 2 || single.Iterations <= 2 {
+		t.Fatalf("%d %d iterations", limited.Iterations, single.Iterations)
+	}
+	if loose, _ := Cluster(2, data, 8, Options{Tolerance: 1}); loose.Iterations != 1 {
+		t.Fatalf("%d iterations with a loose tolerance", loose.Iterations)
+	}
+
+	// the legacy api returns the final centroids
+	labels, centroids, _ := Kmeans(2, data, 8, SquaredEuclideanDistance, -1)
+	for cluster, centroid := range centroids {
+		mean, count := make(Observation, 2), 0
+		for i, label := range labels {
+			if label == cluster {
+				mean.Add(data[i])
+				count++
+			}
+		}
+		mean.Mul(1 / float64(count))
+		if shift(mean, centroid) > 1e-9 {
+			t.Fatalf("%d: %v is not the mean %v", cluster, centroid, mean)
+		}
+	}
+}
+
+func TestEmptyCluster(t *testing.T) {
+	data := []ClusteredObservation{
+		{Observation: Observation{0}},
+		{Observation: Observation{1}},
+		{Observation: Observation{10}},
+		{Observation: Observation{11}},
+	}
+	// no observation is near the third mean, so it is re-seeded with the farthest observation
+	mean := []Observation{{0}, {10}, {100}}
+	result, err := kmeans(data, mean, Options{MaxIterations: MaxIterations, Distance: SquaredEuclideanDistance})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sizes := make([]int, len(mean))
+	for _, label := range result.Labels {
+		sizes[label]++
+	}
+	for cluster, centroid := range result.Centroids {
+		if math.IsNaN(centroid[0]) || sizes[cluster] == 0 {
+			t.Fatalf("cluster %d has %d observations and centroid %v", cluster, sizes[cluster], centroid)
+		}
+	}
+
+	// the observations of the middle mean move to the outer means in the last assignment
+	data = []ClusteredObservation{
+		{Observation: Observation{20}},
+		{Observation: Observation{12}},
+		{Observation: Observation{9}},
+		{Observation: Observation{-9}},
+		{Observation: Observation{-12}},
+		{Observation: Observation{-20}},
+	}
+	mean = []Observation{{20}, {0}, {-20}}
+	result, err = kmeans(data, mean, Options{MaxIterations: 1, Distance: SquaredEuclideanDistance})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 9 is the farthest observation, so it re-seeds the middle cluster and the mean it left is moved
+	for cluster, expected := range []float64{16, 9, -16} {
+		if result.Centroids[cluster][0] != expected {
+			t.Fatalf("cluster %d has centroid %v and not %f", cluster, result.Centroids[cluster], expected)
+		}
+	}
+	for i, expected := range []int{0, 0, 1, 2, 2, 2} {
+		if result.Labels[i] != expected {
+			t.Fatalf("observation %d is in cluster %d and not %d", i, result.Labels[i], expected)
+		}
+	}
+
+	// more clusters than observations leaves the extra clusters empty with finite centroids
+	result, err = Cluster(1, [][]float64{{0}, {1}}, 3, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, centroid := range result.Centroids {
+		if math.IsNaN(centroid[0]) {
+			t.Fatalf("centroid %v", centroid)
+		}
+	}
+}
+
+func TestDistanceError(t *testing.T) {
+	data := [][]float64{{0, 0}, {1, 1}, {2}, {3, 3}}
+	for _, distance := range []DistanceFunction{HammingDistance, EuclideanDistance, SquaredEuclideanDistance} {
+		if _, err := Cluster(1, data, 2, Options{Distance: distance}); err != ErrLength {
+			t.Fatalf("cluster: %v", err)
+		}
+		if _, _, err := Kmeans(1, data, 2, distance, 0); err != ErrLength {
+			t.Fatalf("kmeans: %v", err)
+		}
+		if _, err := Consensus(1, data, 2, 4, ConsensusOptions{Options: Options{Distance: distance}}); err != ErrLength {
+			t.Fatalf("consensus: %v", err)
+		}
+	}
+	if _, err := MinkowskiDistance([]float64{1}, []float64{1, 2}, 3); err != ErrLength {
+		t.Fatalf("minkowski: %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package kmeans
+
+import (
+	"math"
+	"math/rand"
+)
+
+const (
+	// BatchSize is the default number of observations in a batch of MiniBatch
+	BatchSize = 1024
+	// MaxNoImprovement is the number of batches without a smaller average batch inertia
+	// after which mini-batch k-means stops
+	MaxNoImprovement = 10
+)
+
+// miniBatch is mini-batch k-means, each iteration draws a batch of observations with replacement,
+// assigns it to the closest means and moves each mean toward its observations with a step of one over
+// the number of observations the mean has seen. A mean that hasn't seen an observation and gets none of the
+// batch is re-seeded with the observation of the batch that is farthest from its mean. The iterations stop
+// when no mean moves further than the tolerance, when the moving average of the batch inertia stops improving
+// or at the maximum number of iterations, and then the observations are assigned to the final means and the
+// clusters that are left empty are re-seeded
+func miniBatch(rng *rand.Rand, data []ClusteredObservation, seeds []Observation, options Options) (Result, error) {
+	mean, previous := make([]Observation, len(seeds)), make([]Observation, len(seeds))
+	for ii := range mean {
+		mean[ii] = append(Observation{}, seeds[ii]...)
+		previous[ii] = make(Observation, len(seeds[ii]))
+	}
+	counts, mLen := make([]int, len(mean)), make([]int, len(mean))
+	batch, distances := make([]ClusteredObservation, options.BatchSize), make([]float64, options.BatchSize)
+	alpha := math.Min(1, 2*float64(len(batch))/float64(len(data)+1))
+	average, best, noImprovement := 0.0, math.Inf(1), 0
+	iterations := 0
+	for iterations < options.MaxIterations {
+		for ii := range batch {
+			batch[ii] = data[rng.Intn(len(data))]
+		}
+		if _, err := assign(batch, mean, options.Distance, distances); err != nil {
+			return Result{}, err
+		}
+		// only the means that haven't seen an observation are re-seeded, the others count as taken
+		for ii := range mLen {
+			mLen[ii] = min(counts[ii], 1)
+		}
+		for _, p := range batch {
+			mLen[p.ClusterNumber]++
+		}
+		reseed(batch, distances, mLen)
+		for ii := range mean {
+			copy(previous[ii], mean[ii])
+		}
+		inertia := 0.0
+		for ii, p := range batch {
+			inertia += distances[ii] * distances[ii] / float64(len(batch))
+			counts[p.ClusterNumber]++
+			eta, m := 1/float64(counts[p.ClusterNumber]), mean[p.ClusterNumber]
+			for jj, value := range p.Observation {
+				m[jj] += eta * (value - m[jj])
+			}
+		}
+		moved := 0.0
+		for ii := range mean {
+			moved = math.Max(moved, shift(previous[ii], mean[ii]))
+		}
+		iterations++
+		if iterations == 1 {
+			average = inertia
+		} else {
+			average = (1-alpha)*average + alpha*inertia
+		}
+		if average < best {
+			best, noImprovement = average, 0
+		} else {
+			noImprovement++
+		}
+		if moved <= options.Tolerance || noImprovement >= MaxNoImprovement {
+			break
+		}
+	}
+
+	distances = make([]float64, len(data))
+	if _, err := assign(data, mean, options.Distance, distances); err != nil {
+		return Result{}, err
+	}
+	settle(data, mean, distances)
+	return label(data, mean, iterations, options.Distance)
+}
+
+// MiniBatch clusters the data into k clusters with mini-batch k-means, which is Cluster with a
+// batch size that defaults to BatchSize. The batches are drawn from rngSeed, so the result
+// only depends on the seed
+func MiniBatch(rngSeed int64, rawData [][]float64, k int, options Options) (Result, error) {
+	if options.BatchSize <= 0 {
+		options.BatchSize = BatchSize
+	}
+	return Cluster(rngSeed, rawData, k, options)
+}
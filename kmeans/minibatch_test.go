@@ -0,0 +1,126 @@
+package kmeans
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestAssign(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	data, mean := make([]ClusteredObservation, 4096), make([]Observation, 8)
+	for i := range data {
+		data[i].Observation = make(Observation, 8)
+		for ii := range data[i].Observation {
+			data[i].Observation[ii] = rng.NormFloat64()
+		}
+	}
+	for i := range mean {
+		mean[i] = data[rng.Intn(len(data))].Observation
+	}
+	if len(data)*len(mean)*len(mean[0]) < AssignParallel {
+		t.Fatal("the assignment is not parallel")
+	}
+	distances := make([]float64, len(data))
+	changes, err := assign(data, mean, SquaredEuclideanDistance, distances)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes == 0 {
+		t.Fatal("no observation changed its cluster")
+	}
+	for i, p := range data {
+		cluster, distance, _ := near(p, mean, SquaredEuclideanDistance)
+		if cluster != p.ClusterNumber || distance != distances[i] {
+			t.Fatalf("%d: cluster %d != %d", i, p.ClusterNumber, cluster)
+		}
+	}
+	if changes, _ := assign(data, mean, SquaredEuclideanDistance, distances); changes != 0 {
+		t.Fatalf("%d changes", changes)
+	}
+}
+
+func TestMiniBatch(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	data, labels := blobs(rng, 3, 2000)
+	result, err := MiniBatch(1, data, 3, Options{BatchSize: 256})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Iterations < 1 || result.Iterations >= MaxIterations {
+		t.Fatalf("%d iterations", result.Iterations)
+	}
+	clusters := make(map[int]int)
+	for i, label := range result.Labels {
+		if cluster, ok := clusters[labels[i]]; ok && cluster != label {
+			t.Fatalf("%d: blob %d is split", i, labels[i])
+		}
+		clusters[labels[i]] = label
+	}
+	if len(clusters) != 3 {
+		t.Fatalf("%d clusters", len(clusters))
+	}
+	// mini-batch k-means is close to Lloyd's k-means
+	lloyd, _ := Cluster(1, data, 3, Options{})
+	if result.Inertia > 1.01*lloyd.Inertia {
+		t.Fatalf("inertia %f > %f", result.Inertia, lloyd.Inertia)
+	}
+	again, _ := MiniBatch(1, data, 3, Options{BatchSize: 256})
+	for i := range again.Labels {
+		if again.Labels[i] != result.Labels[i] || again.Inertia != result.Inertia {
+			t.Fatal("not deterministic")
+		}
+	}
+}
+
+// points draws 16384 observations with 64 dimensions from overlapping blobs
+func TestMiniBatchEmptyCluster(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	data := make([]ClusteredObservation, 64)
+	for i := range data {
+		data[i].Observation = Observation{float64(i % 8)}
+	}
+	// no observation is near the last mean, so no batch moves it
+	mean := []Observation{{0}, {7}, {100}}
+	result, err := miniBatch(rng, data, mean, Options{MaxIterations: 64, BatchSize: 16, Distance: SquaredEuclideanDistance})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sizes := make([]int, len(mean))
+	for _, label := range result.Labels {
+		sizes[label]++
+	}
+	for cluster, size := range sizes {
+		if size == 0 {
+			t.Fatalf("cluster %d is empty", cluster)
+		}
+	}
+	if result.Centroids[2][0] >= 8 {
+		t.Fatalf("the last mean wasn't re-seeded: %v", result.Centroids[2])
+	}
+}
+
+func points() [][]float64 {
+	rng := rand.New(rand.NewSource(1))
+	data := make([][]float64, 16384)
+	for i := range data {
+		data[i] = make([]float64, 64)
+		for ii := range data[i] {
+			data[i][ii] = rng.NormFloat64() + float64(i%8)/8
+		}
+	}
+	return data
+}
+
+func BenchmarkCluster(b *testing.B) {
+	data := points()
+	for b.Loop() {
+		Cluster(1, data, 8, Options{})
+	}
+}
+
+func BenchmarkMiniBatch(b *testing.B) {
+	data := points()
+	for b.Loop() {
+		MiniBatch(1, data, 8, Options{})
+	}
+}
@@ -0,0 +1,301 @@
+package kmeans
+
+import (
+	"math"
+	"math/rand"
+)
+
+// centroids computes the means of the clusters and their sizes, k is one more than the largest label
+func centroids(rawData [][]float64, labels []int) ([]Observation, []int) {
+	k := 0
+	for _, label := range labels {
+		k = max(k, label+1)
+	}
+	means, sizes := make([]Observation, k), make([]int, k)
+	for i := range means {
+		means[i] = make(Observation, len(rawData[0]))
+	}
+	for i, label := range labels {
+		means[label].Add(rawData[i])
+		sizes[label]++
+	}
+	for i := range means {
+		if sizes[i] > 0 {
+			means[i].Mul(1 / float64(sizes[i]))
+		}
+	}
+	return means, sizes
+}
+
+// Silhouette is the mean silhouette of the observations with euclidean distances, it is between -1 and 1
+// and larger is better. The silhouette of an observation in a cluster of its own is 0
+func Silhouette(rawData [][]float64, labels []int) float64 {
+	_, sizes := centroids(rawData, labels)
+	if len(sizes) < 2 {
+		return 0
+	}
+	sum, distances := 0.0, make([]float64, len(sizes))
+	for i, label := range labels {
+		if sizes[label] < 2 {
+			continue
+		}
+		for c := range distances {
+			distances[c] = 0
+		}
+		for j, other := range labels {
+			distance, _ := EuclideanDistance(rawData[i], rawData[j])
+			distances[other] += distance
+		}
+		a, b := distances[label]/float64(sizes[label]-1), math.Inf(1)
+		for c, distance := range distances {
+			if c != label && sizes[c] > 0 {
+				b = math.Min(b, distance/float64(sizes[c]))
+			}
+		}
+		if math.IsInf(b, 1) {
+			continue
+		}
+		sum += (b - a) / math.Max(a, b)
+	}
+	return sum / float64(len(labels))
+}
+
+// SilhouetteSamples is the default number of observations of the sampled silhouette of SelectK
+const SilhouetteSamples = 1024
+
+// SampledSilhouette is the silhouette of samples observations drawn without replacement from rngSeed,
+// it costs O(samples^2) distances instead of O(n^2) and is the silhouette of all the observations
+// if samples is at least their number
+func SampledSilhouette(rngSeed int64, rawData [][]float64, labels []int, samples int) float64 {
+	if samples >= len(rawData) {
+		return Silhouette(rawData, labels)
+	}
+	rng := rand.New(rand.NewSource(rngSeed))
+	data, sampled := make([][]float64, samples), make([]int, samples)
+	for i, index := range rng.Perm(len(rawData))[:samples] {
+		data[i], sampled[i] = rawData[index], labels[index]
+	}
+	return Silhouette(data, sampled)
+}
+
+// CalinskiHarabasz is the ratio of the between cluster dispersion to the within cluster dispersion,
+// each divided by its degrees of freedom, larger is better
+func CalinskiHarabasz(rawData [][]float64, labels []int) float64 {
+	means, sizes := centroids(rawData, labels)
+	center := make(Observation, len(rawData[0]))
+	for _, observation := range rawData {
+		center.Add(observation)
+	}
+	center.Mul(1 / float64(len(rawData)))
+	k, n := 0, len(rawData)
+	between, within := 0.0, 0.0
+	for c, mean := range means {
+		if sizes[c] == 0 {
+			continue
+		}
+		k++
+		distance, _ := SquaredEuclideanDistance(mean, center)
+		between += float64(sizes[c]) * distance
+	}
+	for i, label := range labels {
+		distance, _ := SquaredEuclideanDistance(rawData[i], means[label])
+		within += distance
+	}
+	if k < 2 || n <= k || within == 0 {
+		return 0
+	}
+	return (between / float64(k-1)) / (within / float64(n-k))
+}
+
+// DaviesBouldin is the mean over the clusters of the largest ratio of the spread of two clusters
+// to the euclidean distance of their centroids, smaller is better
+func DaviesBouldin(rawData [][]float64, labels []int) float64 {
+	means, sizes := centroids(rawData, labels)
+	spread := make([]float64, len(means))
+	for i, label := range labels {
+		distance, _ := EuclideanDistance(rawData[i], means[label])
+		spread[label] += distance / float64(sizes[label])
+	}
+	sum, k := 0.0, 0
+	for i := range means {
+		if sizes[i] == 0 {
+			continue
+		}
+		k++
+		worst := 0.0
+		for j := range means {
+			if i == j || sizes[j] == 0 {
+				continue
+			}
+			if separation, _ := EuclideanDistance(means[i], means[j]); separation > 0 {
+				worst = math.Max(worst, (spread[i]+spread[j])/separation)
+			}
+		}
+		sum += worst
+	}
+	if k < 2 {
+		return 0
+	}
+	return sum / float64(k)
+}
+
+// Gap is the gap statistic of k clusters, the mean log inertia of k-means on references data sets
+// drawn uniformly from the bounding box of the data minus the log inertia of k-means on the data.
+// The deviation is the standard deviation of the reference log inertia scaled by sqrt(1+1/references).
+// The log inertia isn't defined when k-means fits the data exactly, as when k is the number of observations,
+// so then the gap and the deviation are zero
+func Gap(rngSeed int64, rawData [][]float64, k, references int, options Options) (gap, deviation float64, err error) {
+	rng := rand.New(rand.NewSource(rngSeed))
+	result, err := Cluster(rng.Int63(), rawData, k, options)
+	if err != nil {
+		return 0, 0, err
+	}
+	if result.Inertia == 0 {
+		return 0, 0, nil
+	}
+	low, high := append([]float64{}, rawData[0]...), append([]float64{}, rawData[0]...)
+	for _, observation := range rawData {
+		for i, value := range observation {
+			low[i], high[i] = math.Min(low[i], value), math.Max(high[i], value)
+		}
+	}
+	logs, mean := make([]float64, references), 0.0
+	for r := range logs {
+		reference := make([][]float64, len(rawData))
+		for i := range reference {
+			reference[i] = make([]float64, len(low))
+			for ii := range reference[i] {
+				reference[i][ii] = low[ii] + rng.Float64()*(high[ii]-low[ii])
+			}
+		}
+		clustering, err := Cluster(rng.Int63(), reference, k, options)
+		if err != nil {
+			return 0, 0, err
+		}
+		if clustering.Inertia == 0 {
+			return 0, 0, nil
+		}
+		logs[r] = math.Log(clustering.Inertia)
+		mean += logs[r] / float64(references)
+	}
+	for _, value := range logs {
+		deviation += (value - mean) * (value - mean) / float64(references)
+	}
+	deviation = math.Sqrt(deviation) * math.Sqrt(1+1/float64(references))
+	return mean - math.Log(result.Inertia), deviation, nil
+}
+
+// Criterion is the score SelectK chooses k with
+type Criterion int
+
+const (
+	// SilhouetteCriterion chooses the k with the largest silhouette
+	SilhouetteCriterion Criterion = iota
+	// GapCriterion chooses the smallest k whose gap is at least the gap of k+1 minus its deviation
+	GapCriterion
+	// CalinskiHarabaszCriterion chooses the k with the largest Calinski-Harabasz score
+	CalinskiHarabaszCriterion
+	// DaviesBouldinCriterion chooses the k with the smallest Davies-Bouldin score
+	DaviesBouldinCriterion
+)
+
+// SelectOptions are the options of SelectK
+type SelectOptions struct {
+	// Options are the options of the k-means runs
+	Options
+	// Criterion is the score that chooses k
+	Criterion Criterion
+	// References is the number of reference data sets of the gap statistic, it defaults to 8
+	References int
+	// Samples is the number of observations of the sampled silhouette, it defaults to SilhouetteSamples
+	Samples int
+}
+
+// Scores are the scores of a clustering into K clusters
+type Scores struct {
+	K          int
+	Labels     []int
+	Inertia    float64
+	Silhouette float64
+	// Gap and GapDeviation are only computed for the GapCriterion
+	Gap              float64
+	GapDeviation     float64
+	CalinskiHarabasz float64
+	DaviesBouldin    float64
+}
+
+// SelectK clusters the data into k clusters for every k from minK to maxK and returns the k chosen by
+// the criterion with the scores of every k. The silhouette is sampled and the gap, which clusters the
+// references for every k, is only computed for the GapCriterion. The silhouette, Calinski-Harabasz and
+// Davies-Bouldin scores aren't defined for one cluster, so k=1 is only chosen by them if it is the only k
+func SelectK(rngSeed int64, rawData [][]float64, minK, maxK int, options SelectOptions) (int, []Scores, error) {
+	if options.References <= 0 {
+		options.References = 8
+	}
+	if options.Samples <= 0 {
+		options.Samples = SilhouetteSamples
+	}
+	minK, maxK = max(1, minK), min(maxK, len(rawData))
+	rng := rand.New(rand.NewSource(rngSeed))
+	scores := make([]Scores, 0, maxK-minK+1)
+	for k := minK; k <= maxK; k++ {
+		result, err := Cluster(rng.Int63(), rawData, k, options.Options)
+		if err != nil {
+			return 0, nil, err
+		}
+		score := Scores{
+			K:                k,
+			Labels:           result.Labels,
+			Inertia:          result.Inertia,
+			Silhouette:       SampledSilhouette(rng.Int63(), rawData, result.Labels, options.Samples),
+			CalinskiHarabasz: CalinskiHarabasz(rawData, result.Labels),
+			DaviesBouldin:    DaviesBouldin(rawData, result.Labels),
+		}
+		if options.Criterion == GapCriterion {
+			score.Gap, score.GapDeviation, err = Gap(rng.Int63(), rawData, k, options.References, options.Options)
+			if err != nil {
+				return 0, nil, err
+			}
+		}
+		scores = append(scores, score)
+	}
+	if len(scores) == 0 {
+		return 0, scores, nil
+	}
+
+	if options.Criterion == GapCriterion {
+		best := 0
+		for i := range scores {
+			if i+1 < len(scores) && scores[i].Gap >= scores[i+1].Gap-scores[i+1].GapDeviation {
+				return scores[i].K, scores, nil
+			}
+			if scores[i].Gap > scores[best].Gap {
+				best = i
+			}
+		}
+		return scores[best].K, scores, nil
+	}
+	score := func(s Scores) float64 {
+		switch options.Criterion {
+		case CalinskiHarabaszCriterion:
+			return s.CalinskiHarabasz
+		case DaviesBouldinCriterion:
+			return -s.DaviesBouldin
+		default:
+			return s.Silhouette
+		}
+	}
+	best := -1
+	for i := range scores {
+		if scores[i].K == 1 {
+			continue
+		}
+		if best == -1 || score(scores[i]) > score(scores[best]) {
+			best = i
+		}
+	}
+	if best == -1 {
+		best = 0
+	}
+	return scores[best].K, scores, nil
+}
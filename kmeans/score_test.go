@@ -0,0 +1,77 @@
+package kmeans
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestScores(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	data, labels := blobs(rng, 3, 50)
+	random := make([]int, len(labels))
+	for i := range random {
+		random[i] = rng.Intn(3)
+	}
+	// the blobs score better than random labels with every score
+	if s, r := Silhouette(data, labels), Silhouette(data, random); s < .8 || s <= r {
+		t.Fatalf("silhouette %f random %f", s, r)
+	}
+	if s, r := CalinskiHarabasz(data, labels), CalinskiHarabasz(data, random); s <= r {
+		t.Fatalf("calinski-harabasz %f random %f", s, r)
+	}
+	if s, r := DaviesBouldin(data, labels), DaviesBouldin(data, random); s >= r {
+		t.Fatalf("davies-bouldin %f random %f", s, r)
+	}
+	one := make([]int, len(labels))
+	if Silhouette(data, one) != 0 || CalinskiHarabasz(data, one) != 0 || DaviesBouldin(data, one) != 0 {
+		t.Fatal("one cluster should score 0")
+	}
+	gap, deviation, err := Gap(1, data, 3, 8, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gap <= 0 || deviation < 0 || math.IsNaN(deviation) {
+		t.Fatalf("gap %f deviation %f", gap, deviation)
+	}
+	// every observation in its own cluster fits the data exactly
+	if gap, deviation, err := Gap(1, data[:8], 8, 8, Options{}); err != nil || gap != 0 || deviation != 0 {
+		t.Fatalf("exact fit: gap %f deviation %f %v", gap, deviation, err)
+	}
+
+	if s, sampled := Silhouette(data, labels), SampledSilhouette(1, data, labels, len(data)); s != sampled {
+		t.Fatalf("silhouette %f sampled %f", s, sampled)
+	}
+	if s, sampled := Silhouette(data, labels), SampledSilhouette(1, data, labels, 64); math.Abs(s-sampled) > .05 {
+		t.Fatalf("silhouette %f sampled %f", s, sampled)
+	}
+}
+
+func TestSelectK(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	data, _ := blobs(rng, 3, 50)
+	for _, criterion := range []Criterion{SilhouetteCriterion, GapCriterion, CalinskiHarabaszCriterion, DaviesBouldinCriterion} {
+		k, scores, err := SelectK(1, data, 1, 6, SelectOptions{Criterion: criterion})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k != 3 {
+			t.Fatalf("criterion %d selected %d", criterion, k)
+		}
+		if len(scores) != 6 {
+			t.Fatalf("%d scores", len(scores))
+		}
+		for i, score := range scores {
+			if score.K != i+1 || len(score.Labels) != len(data) {
+				t.Fatalf("score %d is for k %d", i, score.K)
+			}
+			if criterion != GapCriterion && (score.Gap != 0 || score.GapDeviation != 0) {
+				t.Fatalf("criterion %d computed the gap", criterion)
+			}
+		}
+		again, _, _ := SelectK(1, data, 1, 6, SelectOptions{Criterion: criterion})
+		if again != k {
+			t.Fatal("not deterministic")
+		}
+	}
+}
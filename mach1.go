@@ -57,6 +57,7 @@ func Mach1Mode() {
 	type Line struct {
 		Cluster int
 		Count   int
+		Stddev  float64
 	}
 
 	bible := string(files[1].Data)
@@ -161,15 +162,18 @@ func Mach1Mode() {
 		Size:       50,
 		Divider:    1,
 		Accuracy:   8,
+		Similarity: Kernels[*FlagKernel],
+		Ranker:     Rankers[*FlagRanker],
 	}
 	words = words[:1024]
+	rng := rand.New(rand.NewSource(*FlagSeed))
 	{
-		rng := rand.New(rand.NewSource(1))
 		type Trace struct {
 			Trace []*Vector[Line]
 			Value float64
 		}
 		traces := make([]Trace, 7)
+		mask := NewWordConstraint(words, links)
 		context := "lord"
 		for i := range traces {
 			fmt.Println("trace", i)
@@ -185,43 +189,35 @@ func Mach1Mode() {
 			const weight = 256
 			for ii := range 33 {
 				fmt.Println("word", ii)
-				MorpheusGramSchmidt(rng.Int63(), config, words, func(cs *Matrix[float32]) {
-					fmt.Println("cs")
+				trace := AdjacencyConstraint{Mode: Force}
+				for c, col := range indexes {
+				loop:
 					for i := range words {
-						from := links[words[i].Word]
-						for ii := range words {
-							to := from[words[ii].Word]
-							if to == 0 {
-								cs.Data[i*cs.Cols+ii] = 0
-							}
-						}
-					}
-					for c, col := range indexes {
-					loop:
-						for i := range cs.Rows {
-							if c > 0 && indexes[c-1] == i {
-								cs.Data[i*cs.Cols+col] = weight
-							} else {
-								for _, value := range indexes {
-									if value == i && col != i {
-										continue loop
-									}
+						if c > 0 && indexes[c-1] == i {
+							trace.Set(i, col, weight)
+						} else {
+							for _, value := range indexes {
+								if value == i && col != i {
+									continue loop
 								}
-								cs.Data[i*cs.Cols+col] = weight
 							}
+							trace.Set(i, col, weight)
 						}
 					}
-				})
+				}
+				traced := config
+				traced.Constraints = []EdgeConstraint{mask, trace}
+				result := MorpheusGramSchmidt(rng.Int63(), traced, words)
 				distribution, sum := make([]float64, len(words)), 0.0
-				for _, value := range words {
-					stddev := value.Avg
+				for _, value := range result.Avg {
+					stddev := value
 					if stddev < 0 {
 						stddev = -stddev
 					}
 					sum += stddev
 				}
-				for iii, value := range words {
-					stddev := value.Avg
+				for iii, value := range result.Avg {
+					stddev := value
 					if stddev < 0 {
 						stddev = -stddev
 					}
@@ -243,7 +239,7 @@ func Mach1Mode() {
 						traces[i].Trace = append(traces[i].Trace, words[index])
 						fmt.Println(words[index].Word)
 						state = words[index].Word
-						traces[i].Value += math.Abs(words[index].Avg)
+						traces[i].Value += math.Abs(result.Avg[index])
 						break
 					}
 				}
@@ -261,35 +257,21 @@ func Mach1Mode() {
 		}
 	}
 
-	cov := Morpheus(1, config, words)
-	meta := make([][]float64, len(words))
-	for i := range meta {
-		meta[i] = make([]float64, len(words))
-	}
+	config.Embedding, config.Laplacian = *FlagEmbedding, *FlagLaplacian
+	result := Morpheus(rng.Int63(), config, words)
+	points := result.Points()
 	const k = 2
-	for i := 0; i < 33; i++ {
-		clusters, _, err := kmeans.Kmeans(int64(i+1), cov, k, kmeans.SquaredEuclideanDistance, -1)
-		if err != nil {
-			panic(err)
-		}
-		for i := 0; i < len(meta); i++ {
-			target := clusters[i]
-			for j, v := range clusters {
-				if v == target {
-					meta[i][j]++
-				}
-			}
-		}
-	}
-	clusters, _, err := kmeans.Kmeans(1, meta, k, kmeans.SquaredEuclideanDistance, -1)
+	consensus, err := kmeans.Consensus(rng.Int63(), points, k, 33, kmeans.ConsensusOptions{})
 	if err != nil {
 		panic(err)
 	}
+	clusters := consensus.Labels
 	for i := range words {
 		words[i].Meta.Cluster = clusters[i]
+		words[i].Meta.Stddev = result.Stddev[i]
 	}
 	sort.Slice(words, func(i, j int) bool {
-		return words[i].Stddev < words[j].Stddev
+		return words[i].Meta.Stddev < words[j].Meta.Stddev
 	})
 
 	output, err := os.Create("report.html")
@@ -319,7 +301,7 @@ func Mach1Mode() {
 		fmt.Fprintf(output, "  <td>%s</td>\n", words[i].Word)
 		fmt.Fprintf(output, "  <td>%d</td>\n", words[i].Meta.Count)
 		fmt.Fprintf(output, "  <td>%d</td>\n", words[i].Meta.Cluster)
-		fmt.Fprintf(output, "  <td>%.8f</td>\n", words[i].Stddev)
+		fmt.Fprintf(output, "  <td>%.8f</td>\n", words[i].Meta.Stddev)
 		fmt.Fprintf(output, " </tr>\n")
 	}
 	fmt.Fprintf(output, "</table>\n")
@@ -19,8 +19,6 @@ import (
 	"strings"
 
 	"github.com/pointlander/morpheus/kmeans"
-
-	"github.com/alixaxel/pagerank"
 )
 
 var (
@@ -50,6 +48,16 @@ var (
 	FlagMarkov = flag.Bool("markov", false, "markov mode")
 	// FlagLearn learn the vector database
 	FlagLearn = flag.Bool("learn", false, "learn the vector database")
+	// FlagEmbedding is the number of dimensions of the spectral embedding that is clustered
+	FlagEmbedding = flag.Int("embedding", 0, "number of dimensions of the spectral embedding")
+	// FlagLaplacian embeds with the normalized laplacian
+	FlagLaplacian = flag.Bool("laplacian", false, "embed with the normalized laplacian")
+	// FlagKernel is the similarity kernel
+	FlagKernel = flag.String("kernel", "cosine", "similarity kernel: cosine, dot, rbf, angular or rectified")
+	// FlagRanker is the centrality used to rank the similarity graph
+	FlagRanker = flag.String("ranker", "pagerank", "graph ranker: pagerank, hits, hubs, eigenvector or katz")
+	// FlagSeed is the seed that every random number generator of a mode is derived from
+	FlagSeed = flag.Int64("seed", 1, "seed of the random number generators")
 	// FlagPrompt the prompt to use
 	FlagPrompt = flag.String("prompt", "What is the meaning of life?", "the prompt to use")
 	// cpuprofile profiles the program
@@ -70,13 +78,12 @@ func Lookup(markov *[order]Markov, model *Model) []float32 {
 		vector := model[i][markov[i]]
 		if vector != nil {
 			sum := float32(0.0)
-			for _, value := range vector {
-				sum += float32(value)
-			}
 			result := make([]float32, len(vector))
 			for ii, value := range vector {
-				result[ii] = float32(value) / sum
+				result[ii] = finite(value)
+				sum += result[ii]
 			}
+			stochastic(result, sum)
 			return result
 		}
 	}
@@ -199,13 +206,11 @@ func PageRankMode() {
 		rng := rand.New(rand.NewSource(seed))
 		for range 33 {
 			size := len(words) + len(context)
-			graph := pagerank.NewGraph()
 			adjacency := NewMatrix(size, size, make([]float32, size*size)...)
 			for i := range words {
 				from := links[words[i].Word]
 				for ii := range words {
 					to := from[words[ii].Word]
-					graph.Link(uint32(i), uint32(ii), float64(to))
 					adjacency.Data[i*adjacency.Cols+ii] = float32(to)
 				}
 			}
@@ -215,7 +220,6 @@ func PageRankMode() {
 					for _, value := range adjacency.Data[ii*adjacency.Cols : ii*adjacency.Cols+length] {
 						sum += value
 					}
-					graph.Link(uint32(ii), uint32(length+i), float64(sum/8))
 					adjacency.Data[ii*adjacency.Cols+length+i] += sum / 8
 				}
 				copy(adjacency.Data[(length+i)*adjacency.Cols:(length+i+1)*adjacency.Cols],
@@ -225,15 +229,14 @@ func PageRankMode() {
 					for _, value := range adjacency.Data[(length+i-1)*adjacency.Cols : (length+i-1)*adjacency.Cols+length] {
 						sum += value
 					}
-					graph.Link(uint32(length+i-1), uint32(length+i), float64(sum/8))
 					adjacency.Data[(length+i-1)*adjacency.Cols+length+i] += sum / 8
 				}
 			}
 			//result := PageRank(1.0, 33, rng.Uint32(), adjacency)
-			result := make([]float64, size)
-			graph.Rank(1.0, 1e-3, func(node uint32, rank float64) {
-				result[node] = rank
-			})
+			result, err := FromDense(adjacency).PageRank(1.0, 1e-3)
+			if err != nil {
+				panic(err)
+			}
 			distribution, sum := make([]float64, len(words)), 0.0
 			for _, value := range result[:length] {
 				if value < 0 {
@@ -264,7 +267,7 @@ func PageRankMode() {
 		}
 	}
 
-	rng := rand.New(rand.NewSource(1))
+	rng := rand.New(rand.NewSource(*FlagSeed))
 	for range 8 {
 		go process(rng.Int63())
 	}
@@ -286,7 +289,7 @@ func PageRankMode() {
 
 // RandomMode random mode
 func RandomMode() {
-	rng := rand.New(rand.NewSource(1))
+	rng := rand.New(rand.NewSource(*FlagSeed))
 	iris := Load()
 	rl1 := NewMatrix(4, 4, make([]float64, 4*4)...)
 	for i := range rl1.Data {
@@ -351,29 +354,12 @@ func RandomMode() {
 			compressed[i] = append(compressed[i], data[i][value.Index])
 		}
 	}
-	meta := make([][]float64, len(iris))
-	for i := range meta {
-		meta[i] = make([]float64, len(iris))
-	}
 	const k = 3
-	for i := 0; i < 33; i++ {
-		clusters, _, err := kmeans.Kmeans(int64(i+1), compressed, k, kmeans.SquaredEuclideanDistance, -1)
-		if err != nil {
-			panic(err)
-		}
-		for i := 0; i < len(meta); i++ {
-			target := clusters[i]
-			for j, v := range clusters {
-				if v == target {
-					meta[i][j]++
-				}
-			}
-		}
-	}
-	clusters, _, err := kmeans.Kmeans(1, meta, k, kmeans.SquaredEuclideanDistance, -1)
+	consensus, err := kmeans.Consensus(rng.Int63(), compressed, k, 33, kmeans.ConsensusOptions{})
 	if err != nil {
 		panic(err)
 	}
+	clusters := consensus.Labels
 	for i, row := range rows {
 		fmt.Println(row.Label, clusters[i])
 		rows[i].Cluster = clusters[i]
@@ -506,20 +492,21 @@ func RandomMode() {
 	}
 }
 
-// Rank calculates the page rank based entropy
+// Rank calculates the page rank based entropy of the cosine similarity graph, the negative
+// similarities move rank by their magnitude as described in CSR.PageRank
 func Rank(vectors [][]float32) float64 {
-	graph := pagerank.NewGraph()
+	adjacency := NewMatrix(len(vectors), len(vectors), make([]float64, len(vectors)*len(vectors))...)
 	for ii := range vectors {
 		a := NewMatrix(256, 1, vectors[ii]...)
 		for iii := range vectors {
 			b := NewMatrix(256, 1, vectors[iii]...)
-			graph.Link(uint32(ii), uint32(iii), float64(a.CS(b)))
+			adjacency.Data[ii*adjacency.Cols+iii] = float64(a.CS(b))
 		}
 	}
-	result := make([]float64, len(vectors))
-	graph.Rank(1.0, 1e-3, func(node uint32, rank float64) {
-		result[node] = rank
-	})
+	result, err := FromDense(adjacency).PageRank(1.0, 1e-3)
+	if err != nil {
+		panic(err)
+	}
 	entropy := 0.0
 	for _, value := range result {
 		if value == 0 {
@@ -532,7 +519,7 @@ func Rank(vectors [][]float32) float64 {
 
 // MarkovMode is the markov mode
 func MarkovMode() {
-	rng := rand.New(rand.NewSource(1))
+	rng := rand.New(rand.NewSource(*FlagSeed))
 
 	const (
 		size = 256
@@ -684,6 +671,13 @@ func MarkovMode() {
 func main() {
 	flag.Parse()
 
+	if _, ok := Kernels[*FlagKernel]; !ok {
+		panic(fmt.Errorf("unknown kernel %s", *FlagKernel))
+	}
+	if _, ok := Rankers[*FlagRanker]; !ok {
+		panic(fmt.Errorf("unknown ranker %s", *FlagRanker))
+	}
+
 	if *cpuprofile != "" {
 		f, err := os.Create(*cpuprofile)
 		if err != nil {
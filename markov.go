@@ -0,0 +1,107 @@
+// Copyright 2025 The Morpheus Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import "math/rand"
+
+// MarkovOutput is the matrix that is computed from a markov model
+type MarkovOutput int
+
+const (
+	// Conditional is the probability of the previous node given the node, the absolute values of each row sum to one
+	Conditional MarkovOutput = iota
+	// Flow is the stationary probability of each step, the absolute values of the whole matrix sum to one
+	Flow
+)
+
+// MarkovModel is a markov model of the steps of random walks. The steps between pairs of nodes are always
+// counted and the second order counts are optional: the state of a walk is the previous node and the node
+// and the model counts the next node of each state that was left. A step along a negative
+// edge is counted as negative, so every probability of the model is a signed count divided by the sum of
+// the absolute counts: the absolute values sum to one and a probability is negative when the steps were
+// taken along negative edges
+type MarkovModel[T Float] struct {
+	// Nodes is the number of nodes
+	Nodes int
+	// Steps[next*Nodes+node] is the signed number of steps from node to next
+	Steps []int64
+	// Counts[prev*Nodes+node][next] is the signed number of steps from node to next after a step from prev
+	// to node, only the states that were left are stored and it is nil without the second order
+	Counts map[int][]int64
+}
+
+// probabilities divides the signed counts by the sum of their absolute values, without any counts p is uniform
+func probabilities[T Float](p []T, counts []int64) {
+	sum := int64(0)
+	for i, value := range counts {
+		p[i] = T(value)
+		sum += abs(value)
+	}
+	stochastic(p, T(sum))
+}
+
+// Output computes the conditional or the flow matrix
+func (m MarkovModel[T]) Output(output MarkovOutput) Matrix[T] {
+	if output == Flow {
+		return m.Flow()
+	}
+	return m.Conditional()
+}
+
+// Conditional computes the probability of the previous node given the node, row i is the node and
+// column j is the previous node. The rows of nodes that were never visited are uniform
+func (m MarkovModel[T]) Conditional() Matrix[T] {
+	n := m.Nodes
+	p := NewMatrix(n, n, make([]T, n*n)...)
+	for i := range n {
+		probabilities(p.Data[i*n:(i+1)*n], m.Steps[i*n:(i+1)*n])
+	}
+	return p
+}
+
+// Flow computes the stationary probability of each step, row i is the node and column j is the
+// previous node. Without any steps the flow is uniform
+func (m MarkovModel[T]) Flow() Matrix[T] {
+	n := m.Nodes
+	p := NewMatrix(n, n, make([]T, n*n)...)
+	probabilities(p.Data, m.Steps)
+	return p
+}
+
+// Transition computes the probability of each next node given the previous node and the node, the
+// transition of a state that was never left is uniform
+func (m MarkovModel[T]) Transition(prev, node int) []T {
+	p := make([]T, m.Nodes)
+	probabilities(p, m.Counts[prev*m.Nodes+node])
+	return p
+}
+
+// Walk samples a walk of steps nodes that starts after the step from prev to node, each next node is drawn
+// with the absolute probability of the transition and a walk at a state that was never left continues from
+// a uniformly drawn node
+func (m MarkovModel[T]) Walk(rng *rand.Rand, prev, node, steps int) []int {
+	n := m.Nodes
+	walk := make([]int, 0, steps)
+	for range steps {
+		counts := m.Counts[prev*n+node]
+		total := int64(0)
+		for _, value := range counts {
+			total += abs(value)
+		}
+		next := 0
+		if total == 0 {
+			next = rng.Intn(n)
+		} else {
+			selected := rng.Int63n(total)
+			for selected >= abs(counts[next]) {
+				selected -= abs(counts[next])
+				next++
+			}
+		}
+		walk = append(walk, next)
+		prev, node = node, next
+	}
+	return walk
+}
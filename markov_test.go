@@ -0,0 +1,169 @@
+// Copyright 2025 The Morpheus Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestMarkovModel(t *testing.T) {
+	const n = 5
+	adj := NewMatrix(n, n, make([]float64, n*n)...)
+	adj.Data[0*n+1] = 1.0
+	adj.Data[0*n+2] = 2.0
+	adj.Data[1*n+2] = 3.0
+	adj.Data[1*n+3] = 4.0
+	adj.Data[2*n+0] = 5.0
+	adj.Data[3*n+0] = 1.0
+	// node 4 has no incoming edges so without teleports it is never visited
+	adj.Data[4*n+0] = 1.0
+	weights := append([]float64{}, adj.Data...)
+	model := LearnMarkov(1.0, 256, 1, adj)
+
+	conditional, flow := model.Conditional(), model.Flow()
+	total := 0.0
+	for i := range n {
+		sum, visits := 0.0, 0.0
+		for ii := range n {
+			value := conditional.Data[i*n+ii]
+			if math.IsNaN(value) {
+				t.Fatalf("%d %d is NaN", i, ii)
+			}
+			sum += value
+			visits += flow.Data[i*n+ii]
+		}
+		total += visits
+		if math.Abs(sum-1) > 1e-9 {
+			t.Fatalf("conditional row %d sums to %f", i, sum)
+		}
+		if i == 4 {
+			if visits != 0 || conditional.Data[i*n] != 1.0/n {
+				t.Fatalf("node 4 was visited: %f %f", conditional.Data[i*n], visits)
+			}
+			continue
+		}
+		for ii := range n {
+			if diff := math.Abs(flow.Data[i*n+ii]/visits - conditional.Data[i*n+ii]); diff > 1e-9 {
+				t.Fatalf("%d %d: flow and conditional differ by %g", i, ii, diff)
+			}
+		}
+	}
+	if math.Abs(total-1) > 1e-9 {
+		t.Fatalf("flow sums to %f", total)
+	}
+	if output := model.Output(Flow); output.Data[0] != flow.Data[0] {
+		t.Fatal("output is not the flow")
+	}
+
+	// the second order counts sum to the steps and are only learned when asked for
+	steps := make([]int64, n*n)
+	for state, counts := range model.Counts {
+		for next, value := range counts {
+			steps[next*n+state%n] += value
+		}
+	}
+	pairs := learnMarkov(false, 1.0, 256, 1, NewMatrix(n, n, append([]float64{}, weights...)...))
+	for i, value := range steps {
+		if value != model.Steps[i] || value != pairs.Steps[i] {
+			t.Fatalf("step %d: %d %d %d", i, value, model.Steps[i], pairs.Steps[i])
+		}
+	}
+	if pairs.Counts != nil {
+		t.Fatal("the pairs have second order counts")
+	}
+
+	// the walks are first order so every visited state moves with the edges of its node
+	for state, counts := range model.Counts {
+		prev, node := state/n, state%n
+		visits, out := int64(0), 0.0
+		for ii, value := range counts {
+			visits += value
+			out += weights[node*n+ii]
+		}
+		if visits == 0 {
+			t.Fatalf("state %d %d was stored without being left", prev, node)
+		}
+		if weights[prev*n+node] == 0 {
+			t.Fatalf("state %d %d was visited without an edge", prev, node)
+		}
+		// six standard deviations of the sampled probabilities
+		tolerance := 3 / math.Sqrt(float64(visits))
+		for ii, value := range model.Transition(prev, node) {
+			expected := weights[node*n+ii] / out
+			if diff := math.Abs(value - expected); diff > tolerance {
+				t.Fatalf("%d %d %d: the transition differs from the edges by %f", prev, node, ii, diff)
+			}
+		}
+	}
+}
+
+func TestMarkovSecondOrder(t *testing.T) {
+	const n = 3
+	model := MarkovModel[float64]{
+		Nodes:  n,
+		Steps:  make([]int64, n*n),
+		Counts: make(map[int][]int64),
+	}
+	count := func(prev, node, next int, value int64) {
+		state := prev*n + node
+		if model.Counts[state] == nil {
+			model.Counts[state] = make([]int64, n)
+		}
+		model.Steps[next*n+node] += value - model.Counts[state][next]
+		model.Counts[state][next] = value
+	}
+	// node 1 goes back to where the walk came from, which no first order chain can do
+	count(0, 1, 0, 2)
+	count(2, 1, 2, 3)
+	count(1, 0, 1, 1)
+	count(1, 2, 1, 1)
+	rng := rand.New(rand.NewSource(1))
+	for i, node := range model.Walk(rng, 0, 1, 8) {
+		if expected := []int{0, 1, 0, 1, 0, 1, 0, 1}[i]; node != expected {
+			t.Fatalf("step %d: %d != %d", i, node, expected)
+		}
+	}
+	for i, node := range model.Walk(rng, 2, 1, 4) {
+		if expected := []int{2, 1, 2, 1}[i]; node != expected {
+			t.Fatalf("step %d: %d != %d", i, node, expected)
+		}
+	}
+
+	// a negative count is a negative probability, the walk draws it with its absolute value
+	count(0, 1, 0, -3)
+	count(0, 1, 2, 1)
+	transition, conditional := model.Transition(0, 1), model.Conditional()
+	for i, expected := range []float64{-.75, 0, .25} {
+		if value := transition[i]; value != expected {
+			t.Fatalf("transition %d: %f != %f", i, value, expected)
+		}
+	}
+	for i := range n {
+		sum := 0.0
+		for ii := range n {
+			sum += math.Abs(conditional.Data[i*n+ii])
+		}
+		if math.Abs(sum-1) > 1e-9 {
+			t.Fatalf("conditional row %d sums to %f", i, sum)
+		}
+	}
+	for i, value := range model.Transition(2, 0) {
+		if value != 1.0/n {
+			t.Fatalf("the transition %d of a state that was never left is %f", i, value)
+		}
+	}
+	const samples = 1 << 14
+	back := 0
+	for range samples {
+		if model.Walk(rng, 0, 1, 1)[0] == 0 {
+			back++
+		}
+	}
+	if p := float64(back) / samples; math.Abs(p-.75) > .02 {
+		t.Fatalf("the walk went back with probability %f", p)
+	}
+}
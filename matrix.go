@@ -10,15 +10,23 @@ import (
 	"io"
 	"math"
 	"os"
-	"runtime"
+	"sort"
+	"sync"
 	"sync/atomic"
 
+	"github.com/pointlander/morpheus/parallel"
 	"github.com/pointlander/morpheus/vector"
 )
 
 const (
 	// S is the scaling factor for the softmax
 	S = 1.0 - 1e-300
+	// MaxJacobiSweeps is the maximum number of sweeps of the jacobi eigenvalue algorithm
+	MaxJacobiSweeps = 64
+	// MulTBlock is the number of rows of each matrix in a block of MulT
+	MulTBlock = 64
+	// MulTParallel is the number of multiplications above which MulT computes the blocks in parallel
+	MulTParallel = 1 << 18
 )
 
 // Number is a number
@@ -26,6 +34,32 @@ type Float interface {
 	float32 | float64
 }
 
+// The operations on matrices follow one policy for values that would produce NaN:
+// NaN and infinite inputs are treated as zero, a row that should be a distribution
+// but sums to zero is uniform, a row with a zero norm stays zero when it is scaled
+// to unit length, and a pagerank row without weight teleports
+
+// finite returns x or zero if x is NaN or infinite
+func finite[T Float](x T) T {
+	if x != x || math.IsInf(float64(x), 0) {
+		return 0
+	}
+	return x
+}
+
+// stochastic divides the values by their sum or makes them uniform if the sum is zero
+func stochastic[T Float](values []T, sum T) {
+	if sum == 0 {
+		for i := range values {
+			values[i] = 1 / T(len(values))
+		}
+		return
+	}
+	for i, value := range values {
+		values[i] = value / sum
+	}
+}
+
 // Size is the size of the matrix
 type Size struct {
 	Name string
@@ -92,27 +126,51 @@ func (s Set[T]) Named(name string) Matrix[T] {
 	return *s.ByName[name]
 }
 
-// MulT multiplies two matrices and computes the transpose
-func (m Matrix[T]) MulT(n Matrix[T]) Matrix[T] {
+// MulT multiplies two matrices and computes the transpose, the product is stored in the optional
+// destination buffer if it is large enough, the buffer must not overlap m or n. The product is
+// computed in blocks of MulTBlock rows of m and n, which are computed in parallel for large matrices
+func (m Matrix[T]) MulT(n Matrix[T], dst ...[]T) Matrix[T] {
 	if m.Cols != n.Cols {
 		panic(fmt.Errorf("%d != %d", m.Cols, n.Cols))
 	}
-	columns := m.Cols
+	columns, size := m.Cols, m.Rows*n.Rows
 	o := Matrix[T]{
 		Size: Size{
 			Cols: m.Rows,
 			Rows: n.Rows,
 		},
-		Data: make([]T, 0, m.Rows*n.Rows),
 	}
-	lenn, lenm := len(n.Data), len(m.Data)
-	for i := 0; i < lenn; i += columns {
-		nn := n.Data[i : i+columns]
-		for j := 0; j < lenm; j += columns {
-			mm := m.Data[j : j+columns]
-			o.Data = append(o.Data, dot(mm, nn))
+	if len(dst) == 1 && cap(dst[0]) >= size {
+		o.Data = dst[0][:size]
+	} else {
+		o.Data = make([]T, size)
+	}
+	if columns == 0 {
+		clear(o.Data)
+		return o
+	}
+	block := func(i, j int) {
+		for ii := i; ii < min(i+MulTBlock, n.Rows); ii++ {
+			nn, out := n.Data[ii*columns:(ii+1)*columns], o.Data[ii*m.Rows:(ii+1)*m.Rows]
+			for jj := j; jj < min(j+MulTBlock, m.Rows); jj++ {
+				out[jj] = dot(m.Data[jj*columns:(jj+1)*columns], nn)
+			}
+		}
+	}
+	if size*columns < MulTParallel {
+		for i := 0; i < n.Rows; i += MulTBlock {
+			for j := 0; j < m.Rows; j += MulTBlock {
+				block(i, j)
+			}
 		}
+		return o
 	}
+
+	blocks := (m.Rows + MulTBlock - 1) / MulTBlock
+	total := blocks * ((n.Rows + MulTBlock - 1) / MulTBlock)
+	parallel.For(total, func(index int) {
+		block((index/blocks)*MulTBlock, (index%blocks)*MulTBlock)
+	})
 	return o
 }
 
@@ -181,7 +239,7 @@ func (m Matrix[T]) Softmax(t T) Matrix[T] {
 	output := NewMatrix[T](m.Cols, m.Rows)
 	max := T(0.0)
 	for _, v := range m.Data {
-		v /= t
+		v = finite(v / t)
 		if v > max {
 			max = v
 		}
@@ -191,12 +249,11 @@ func (m Matrix[T]) Softmax(t T) Matrix[T] {
 		sum := T(0.0)
 		values := make([]T, m.Cols)
 		for j, value := range m.Data[i : i+m.Cols] {
-			values[j] = T(math.Exp(float64(value/t - s)))
+			values[j] = T(math.Exp(float64(finite(value/t) - s)))
 			sum += values[j]
 		}
-		for _, value := range values {
-			output.Data = append(output.Data, value/sum)
-		}
+		stochastic(values, sum)
+		output.Data = append(output.Data, values...)
 	}
 	return output
 }
@@ -398,9 +455,7 @@ func dot[T Float](x, y []T) (z T) {
 	case []float64:
 		switch y := any(y).(type) {
 		case []float64:
-			for i := range x {
-				z += T(x[i] * y[i])
-			}
+			z = T(vector.Dot64(x, y))
 		}
 	case []float32:
 		switch y := any(y).(type) {
@@ -423,9 +478,10 @@ func sqrt[T Float](x T) (z T) {
 
 func softmax[T Float](values []T) {
 	max := T(0.0)
-	for _, v := range values {
-		if v > max {
-			max = v
+	for j, v := range values {
+		values[j] = finite(v)
+		if values[j] > max {
+			max = values[j]
 		}
 	}
 	s := max * S
@@ -434,9 +490,7 @@ func softmax[T Float](values []T) {
 		values[j] = T(math.Exp(float64(value - s)))
 		sum += values[j]
 	}
-	for j, value := range values {
-		values[j] = value / sum
-	}
+	stochastic(values, sum)
 }
 
 // CS implements cosine similarity
@@ -447,12 +501,15 @@ func (m Matrix[T]) CS(n Matrix[T]) T {
 		md := m.Data[i : i+m.Cols]
 		nd := n.Data[i : i+m.Cols]
 		ab, aa, bb := dot(md, nd), dot(md, md), dot(nd, nd)
-		if aa <= 0 || bb <= 0 {
+		if !(aa > 0 && bb > 0) || math.IsInf(float64(aa), 0) || math.IsInf(float64(bb), 0) {
 			continue
 		}
-		sum += ab / (T(math.Sqrt(float64(aa))) * T(math.Sqrt(float64(bb))))
+		sum += finite(ab / (T(math.Sqrt(float64(aa))) * T(math.Sqrt(float64(bb)))))
 		count++
 	}
+	if count == 0 {
+		return 0
+	}
 	return sum / count
 }
 
@@ -460,17 +517,18 @@ func (m Matrix[T]) CS(n Matrix[T]) T {
 func (m Matrix[T]) Unit() Matrix[T] {
 	o := NewMatrix[T](m.Cols, m.Rows)
 	for i := 0; i < len(m.Data); i += m.Cols {
-		md := m.Data[i : i+m.Cols]
-		aa := dot(md, md)
+		start := len(o.Data)
+		for _, value := range m.Data[i : i+m.Cols] {
+			o.Data = append(o.Data, finite(value))
+		}
+		od := o.Data[start:]
+		aa := dot(od, od)
 		if aa <= 0 {
-			for _, value := range md {
-				o.Data = append(o.Data, value)
-			}
 			continue
 		}
 		aa = T(math.Sqrt(float64(aa)))
-		for _, value := range md {
-			o.Data = append(o.Data, value/aa)
+		for j, value := range od {
+			od[j] = value / aa
 		}
 	}
 	return o
@@ -529,18 +587,69 @@ func selfAttention[T Float](input Matrix[T]) [InputSize]T {
 	return output
 }
 
-// RNG is a random number generator
+// RNG is a 32 bit galois LFSR random number generator, it is a rand.Source64 and can be
+// split into streams for goroutines
 type RNG uint32
 
 // LFSRMask is a LFSR mask with a maximum period
 const LFSRMask = 0x80000057
 
-// Next returns the next random number
+// LFSRPeriod is the period of the LFSR
+const LFSRPeriod = 1<<32 - 1
+
+// clock is one clock of the LFSR
+func clock(state uint32) uint32 {
+	return (state >> 1) ^ (-(state & 1) & LFSRMask)
+}
+
+// clocks is the state after eight clocks of the LFSR for each low byte of the state,
+// eight clocks of a state are the state shifted by eight xor the entry of its low byte
+var clocks = func() (table [256]uint32) {
+	for i := range table {
+		state := uint32(i)
+		for range 8 {
+			state = clock(state)
+		}
+		table[i] = state
+	}
+	return table
+}()
+
+// NewRNG creates a random number generator from a seed, the zero state is replaced
+// because the LFSR never leaves it
+func NewRNG(seed int64) RNG {
+	var r RNG
+	r.Seed(seed)
+	return r
+}
+
+// Seed seeds the random number generator with the two halves of seed
+func (r *RNG) Seed(seed int64) {
+	state := uint32(seed) ^ uint32(seed>>32)
+	if state == 0 {
+		state = 1
+	}
+	*r = RNG(state)
+}
+
+// Next clocks the LFSR 32 times and returns the state, so every bit of the result is new
 func (r *RNG) Next() uint32 {
-	lfsr := *r
-	lfsr = (lfsr >> 1) ^ (-(lfsr & 1) & LFSRMask)
-	*r = lfsr
-	return uint32(lfsr)
+	lfsr := uint32(*r)
+	for range 4 {
+		lfsr = (lfsr >> 8) ^ clocks[lfsr&0xFF]
+	}
+	*r = RNG(lfsr)
+	return lfsr
+}
+
+// Uint64 returns a uniform uint64
+func (r *RNG) Uint64() uint64 {
+	return uint64(r.Next())<<32 | uint64(r.Next())
+}
+
+// Int63 returns a uniform non negative int64
+func (r *RNG) Int63() int64 {
+	return int64(r.Uint64() >> 1)
 }
 
 // Float32 returns a uniform float32
@@ -558,47 +667,130 @@ func (r *RNG) Intn(n int) int {
 	return int(v % uint32(n))
 }
 
-// PageRank is a counting based pagerank implementation
-func PageRank[T Float](a float32, e int, seed uint32, adj Matrix[T]) Matrix[T] {
+// Split divides the period into n+1 equal parts, r keeps the first part and stream i starts at
+// part i+1, so the n streams don't overlap each other or r for LFSRPeriod/(n+1) clocks
+func (r *RNG) Split(n int) []RNG {
+	streams, jump := make([]RNG, n), advance(LFSRPeriod/uint64(n+1))
+	state := uint32(*r)
+	for i := range streams {
+		state = jump.apply(state)
+		streams[i] = RNG(state)
+	}
+	return streams
+}
+
+// transition is a linear map of the state of the LFSR, column i is the image of bit i
+type transition [32]uint32
+
+// apply maps a state
+func (t *transition) apply(state uint32) (mapped uint32) {
+	for i := range t {
+		if state&(1<<i) != 0 {
+			mapped ^= t[i]
+		}
+	}
+	return mapped
+}
+
+// advance computes the transition of n clocks by squaring the transition of one clock
+func advance(n uint64) transition {
+	var result, power transition
+	for i := range power {
+		result[i], power[i] = 1<<i, clock(1<<i)
+	}
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			for i := range result {
+				result[i] = power.apply(result[i])
+			}
+		}
+		var squared transition
+		for i := range power {
+			squared[i] = power.apply(power[i])
+		}
+		power = squared
+	}
+	return result
+}
+
+// normalizeRows divides the rows of the adjacency matrix by their absolute sum,
+// the rows without weight are left zero so that the walks teleport from them
+func normalizeRows[T Float](adj Matrix[T]) {
 	for i := range adj.Rows {
+		row := adj.Data[i*adj.Cols : (i+1)*adj.Cols]
 		var sum T
-		for ii := range adj.Cols {
-			value := adj.Data[i*adj.Cols+ii]
-			if value < 0 {
-				value = -value
-			}
-			sum += value
+		for ii, value := range row {
+			row[ii] = finite(value)
+			sum += abs(row[ii])
+		}
+		if sum == 0 {
+			continue
+		}
+		for ii := range row {
+			row[ii] /= sum
 		}
-		for ii := range adj.Cols {
-			adj.Data[i*adj.Cols+ii] /= sum
+	}
+}
+
+// teleporter returns a function that draws the node a walk teleports to, uniformly
+// or from the optional teleport distribution
+func teleporter[T Float](n int, teleport ...[]T) func(rng *RNG) int {
+	if len(teleport) == 0 || teleport[0] == nil {
+		return func(rng *RNG) int {
+			return rng.Intn(n)
 		}
 	}
-	rng := RNG(seed)
+	cdf, total := make([]float32, n), float32(0)
+	for i, value := range teleport[0][:n] {
+		total += float32(abs(finite(value)))
+		cdf[i] = total
+	}
+	if total == 0 {
+		return teleporter[T](n)
+	}
+	return func(rng *RNG) int {
+		selected := rng.Float32() * total
+		return min(sort.Search(n, func(i int) bool {
+			return selected < cdf[i]
+		}), n-1)
+	}
+}
+
+// PageRank is a counting based pagerank implementation, the walks teleport uniformly
+// or from the optional teleport distribution for a personalized pagerank. The walks
+// move with the absolute weights and each step counts the sign of the edge it took,
+// a step that teleports from a node without weight counts as positive. The rank is
+// the signed count of each node divided by the sum of the absolute counts, see
+// CSR.SignedPageRank for the exact value. Each walk draws from its own stream split
+// from the generator of seed, so the rank only depends on seed
+func PageRank[T Float](a float32, e int, seed uint32, adj Matrix[T], teleport ...[]T) Matrix[T] {
+	normalizeRows(adj)
+	rng, jump := NewRNG(int64(seed)), teleporter(adj.Cols, teleport...)
+	streams := rng.Split(e)
 	counts := make([]int64, adj.Cols)
 	iterations := adj.Rows * adj.Cols
-	done := make(chan bool, 8)
-	process := func(seed uint32) {
-		rng, node := RNG(seed), rng.Intn(adj.Cols)
+	process := func(rng *RNG) {
+		node := jump(rng)
 		for range iterations {
 			if rng.Float32() > a {
-				node = rng.Intn(adj.Cols)
+				node = jump(rng)
 			}
 			total, selected, found, negative := T(0.0), T(rng.Float32()), false, false
 			for i, weight := range adj.Data[node*adj.Cols : (node+1)*adj.Cols] {
-				total += weight
 				if weight < 0 {
 					negative = true
 					weight = -weight
 				} else {
 					negative = false
 				}
+				total += weight
 				if selected < total {
 					node, found = i, true
 					break
 				}
 			}
 			if !found {
-				node = rng.Intn(adj.Cols)
+				node, negative = jump(rng), false
 			}
 			counter := &counts[node]
 			if negative {
@@ -607,26 +799,11 @@ func PageRank[T Float](a float32, e int, seed uint32, adj Matrix[T]) Matrix[T] {
 				atomic.AddInt64(counter, 1)
 			}
 		}
-		done <- true
-	}
-
-	index, flights, cpus := 0, 0, runtime.NumCPU()
-	for index < e && flights < cpus {
-		go process(rng.Next())
-		index++
-		flights++
 	}
-	for index < e {
-		<-done
-		flights--
 
-		go process(rng.Next())
-		index++
-		flights++
-	}
-	for range flights {
-		<-done
-	}
+	parallel.For(e, func(index int) {
+		process(&streams[index])
+	})
 
 	sum := int64(0)
 	for _, value := range counts {
@@ -635,6 +812,9 @@ func PageRank[T Float](a float32, e int, seed uint32, adj Matrix[T]) Matrix[T] {
 		}
 		sum += value
 	}
+	if sum == 0 {
+		return NewMatrix(len(counts), 1, uniform[T](len(counts))...)
+	}
 	p := NewMatrix[T](len(counts), 1)
 	for _, value := range counts {
 		p.Data = append(p.Data, T(value)/T(sum))
@@ -642,32 +822,45 @@ func PageRank[T Float](a float32, e int, seed uint32, adj Matrix[T]) Matrix[T] {
 	return p
 }
 
-// PageRankMarkov is a counting  pagerank implementation with a markov model
-func PageRankMarkov[T Float](a float32, e int, seed uint32, adj Matrix[T]) Matrix[T] {
-	for i := range adj.Rows {
-		var sum T
-		for ii := range adj.Cols {
-			value := adj.Data[i*adj.Cols+ii]
-			if value < 0 {
-				value = -value
-			}
-			sum += value
-		}
-		for ii := range adj.Cols {
-			adj.Data[i*adj.Cols+ii] /= sum
-		}
-	}
-	rng := RNG(seed)
-	counts := make([]int64, adj.Cols*adj.Rows)
+// PageRankMarkov is a counting  pagerank implementation with a markov model, the walks teleport
+// uniformly or from the optional teleport distribution. Row i is the conditional probability of
+// the node a walk came from given that it is at node i
+func PageRankMarkov[T Float](a float32, e int, seed uint32, adj Matrix[T], teleport ...[]T) Matrix[T] {
+	return learnMarkov(false, a, e, seed, adj, teleport...).Conditional()
+}
+
+// LearnMarkov counts the steps of the walks of the counting pagerank between pairs of nodes and from each
+// step between a pair of nodes to the next node, a step along a negative edge is counted as negative. The
+// first step of a walk has no previous step and isn't counted
+func LearnMarkov[T Float](a float32, e int, seed uint32, adj Matrix[T], teleport ...[]T) MarkovModel[T] {
+	return learnMarkov(true, a, e, seed, adj, teleport...)
+}
+
+// learnMarkov is LearnMarkov that only counts the steps from each step to the next node if second is true
+func learnMarkov[T Float](second bool, a float32, e int, seed uint32, adj Matrix[T], teleport ...[]T) MarkovModel[T] {
+	normalizeRows(adj)
+	rng, jump := NewRNG(int64(seed)), teleporter(adj.Cols, teleport...)
+	streams := rng.Split(e)
+	n := adj.Cols
+	steps := make([]int64, n*n)
+	var counts map[int][]int64
+	if second {
+		counts = make(map[int][]int64)
+	}
+	var lock sync.Mutex
 	iterations := adj.Rows * adj.Cols
-	done := make(chan bool, 8)
-	process := func(seed uint32) {
-		rng, node, prev := RNG(seed), rng.Intn(adj.Cols), 0
+	process := func(rng *RNG) {
+		// the second order counts of the walk are merged when it is done
+		var walk map[int][]int64
+		if second {
+			walk = make(map[int][]int64)
+		}
+		node, prev := jump(rng), -1
 		for range iterations {
 			if rng.Float32() > a {
-				prev, node = node, rng.Intn(adj.Cols)
+				prev, node = node, jump(rng)
 			}
-			total, selected, found, negative := T(0.0), T(rng.Float32()), false, false
+			total, selected, next, negative := T(0.0), T(rng.Float32()), -1, false
 			for i, weight := range adj.Data[node*adj.Cols : (node+1)*adj.Cols] {
 				if weight < 0 {
 					negative = true
@@ -677,55 +870,53 @@ func PageRankMarkov[T Float](a float32, e int, seed uint32, adj Matrix[T]) Matri
 				}
 				total += weight
 				if selected < total {
-					prev, node, found = node, i, true
+					next = i
 					break
 				}
 			}
-			if !found {
-				prev, node = node, rng.Intn(adj.Cols)
+			if next < 0 {
+				next, negative = jump(rng), false
 			}
-			counter := &counts[node*adj.Cols+prev]
-			if negative {
-				atomic.AddInt64(counter, -1)
-			} else {
-				atomic.AddInt64(counter, 1)
+			if prev >= 0 {
+				value := int64(1)
+				if negative {
+					value = -1
+				}
+				atomic.AddInt64(&steps[next*n+node], value)
+				if walk != nil {
+					state := prev*n + node
+					if walk[state] == nil {
+						walk[state] = make([]int64, n)
+					}
+					walk[state][next] += value
+				}
 			}
+			prev, node = node, next
+		}
+		if walk != nil {
+			lock.Lock()
+			for state, values := range walk {
+				if counts[state] == nil {
+					counts[state] = values
+					continue
+				}
+				for next, value := range values {
+					counts[state][next] += value
+				}
+			}
+			lock.Unlock()
 		}
-		done <- true
-	}
-
-	index, flights, cpus := 0, 0, runtime.NumCPU()
-	for index < e && flights < cpus {
-		go process(rng.Next())
-		index++
-		flights++
 	}
-	for index < e {
-		<-done
-		flights--
 
-		go process(rng.Next())
-		index++
-		flights++
-	}
-	for range flights {
-		<-done
-	}
+	parallel.For(e, func(index int) {
+		process(&streams[index])
+	})
 
-	p := NewMatrix[T](adj.Cols, adj.Rows)
-	for i := 0; i < p.Cols*p.Rows; i += p.Cols {
-		sum := int64(0)
-		for _, value := range counts[i : i+p.Cols] {
-			if value < 0 {
-				value = -value
-			}
-			sum += value
-		}
-		for _, value := range counts[i : i+p.Cols] {
-			p.Data = append(p.Data, T(value)/T(sum))
-		}
+	return MarkovModel[T]{
+		Nodes:  n,
+		Steps:  steps,
+		Counts: counts,
 	}
-	return p
 }
 
 // Transformer implements transform inference
@@ -824,6 +1015,77 @@ func (m Matrix[T]) GramSchmidt() Matrix[T] {
 	return n
 }
 
+// Eigen computes the eigenvalues and eigenvectors of a symmetric matrix with the cyclic jacobi algorithm,
+// the eigenvalues are sorted in decreasing order and row i of the returned matrix is the eigenvector of eigenvalue i
+func (m Matrix[T]) Eigen() ([]T, Matrix[T]) {
+	n := m.Rows
+	a := make([]float64, n*n)
+	for i, value := range m.Data[:n*n] {
+		a[i] = float64(value)
+	}
+	v := make([]float64, n*n)
+	for i := range n {
+		v[i*n+i] = 1
+	}
+	for range MaxJacobiSweeps {
+		off, norm := 0.0, 0.0
+		for i := range n {
+			for ii := range n {
+				if i != ii {
+					off += a[i*n+ii] * a[i*n+ii]
+				}
+				norm += a[i*n+ii] * a[i*n+ii]
+			}
+		}
+		if off <= 1e-30*norm {
+			break
+		}
+		for p := 0; p < n-1; p++ {
+			for q := p + 1; q < n; q++ {
+				apq := a[p*n+q]
+				if apq == 0 {
+					continue
+				}
+				theta := (a[q*n+q] - a[p*n+p]) / (2 * apq)
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for k := range n {
+					akp, akq := a[k*n+p], a[k*n+q]
+					a[k*n+p], a[k*n+q] = c*akp-s*akq, s*akp+c*akq
+				}
+				for k := range n {
+					apk, aqk := a[p*n+k], a[q*n+k]
+					a[p*n+k], a[q*n+k] = c*apk-s*aqk, s*apk+c*aqk
+				}
+				for k := range n {
+					vkp, vkq := v[k*n+p], v[k*n+q]
+					v[k*n+p], v[k*n+q] = c*vkp-s*vkq, s*vkp+c*vkq
+				}
+			}
+		}
+	}
+	indexes := make([]int, n)
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return a[indexes[i]*n+indexes[i]] > a[indexes[j]*n+indexes[j]]
+	})
+	values := make([]T, n)
+	vectors := NewMatrix(n, n, make([]T, n*n)...)
+	for i, index := range indexes {
+		values[i] = T(a[index*n+index])
+		for k := range n {
+			vectors.Data[i*n+k] = T(v[k*n+index])
+		}
+	}
+	return values, vectors
+}
+
 // CS is cosine similarity
 func CS[T Float](a []T, b []T) T {
 	return dot(a, b)
@@ -832,11 +1094,11 @@ func CS[T Float](a []T, b []T) T {
 // NCS is normalized cosine similarity
 func NCS[T Float](a []T, b []T) T {
 	aa, bb, ab := dot(a, a), dot(b, b), dot(a, b)
-	if aa <= 0 {
+	if !(aa > 0) {
 		return 0
 	}
-	if bb <= 0 {
+	if !(bb > 0) {
 		return 0
 	}
-	return ab / (sqrt(aa) * sqrt(bb))
+	return finite(ab / (sqrt(aa) * sqrt(bb)))
 }
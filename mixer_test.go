@@ -50,9 +50,9 @@ func TestFiltered(t *testing.T) {
 	b.Add(1)
 	c := NewFiltered()
 	c.Add(1)
-	x := a.Mix()
-	y := b.Mix()
-	z := c.Mix()
+	x := a.Mix(nil)
+	y := b.Mix(nil)
+	z := c.Mix(nil)
 	i := NCS(z[:], x[:])
 	j := NCS(z[:], y[:])
 	if j < i {
@@ -7,8 +7,6 @@ package main
 import (
 	"math"
 	"math/rand"
-
-	"github.com/alixaxel/pagerank"
 )
 
 // Vector is a vector
@@ -16,8 +14,6 @@ type Vector[T any] struct {
 	Meta   T
 	Word   string
 	Vector []float32
-	Avg    float64
-	Stddev float64
 	Next   *Vector[T]
 }
 
@@ -27,423 +23,411 @@ type Config struct {
 	Size       int
 	Divider    int
 	Accuracy   int
+	// Projection defaults to SoftmaxProjection
+	Projection Projection
+	// Similarity defaults to Cosine and is kept by the presets, sparse mode needs a Kernel
+	Similarity Similarity
+	// Filter defaults to AbsFilter
+	Filter EdgeFilter
+	// Ranker defaults to GraphRanker and is kept by the presets except Morpheus3
+	Ranker Ranker
+	// Constraints are applied in order to every edge before the filter
+	Constraints []EdgeConstraint
+	// Neighbors is the number of nearest neighbors each vector is linked to in sparse mode
+	Neighbors int
+	// Search finds the nearest neighbors in sparse mode, defaults to BlockedSearch
+	Search NeighborSearch
+	// Embedding is the number of dimensions of the spectral embedding of the covariance, zero for none
+	Embedding int
+	// Laplacian embeds with the normalized laplacian of the covariance instead of the covariance
+	Laplacian bool
+	// Convergence makes the run adaptive, batches of Iterations iterations are added until the
+	// average, standard deviation and covariance change by at most Convergence between batches,
+	// the batches have one iteration if Iterations is zero
+	Convergence float64
+	// MaxIterations is the most iterations of an adaptive run, defaults to 1024
+	MaxIterations int
+	// Markov is the output of MorpheusMarkov, defaults to Conditional
+	Markov MarkovOutput
 }
 
-func Morpheus[T any](seed int64, config Config, vectors []*Vector[T], mutate ...func(cs *Matrix[float32])) [][]float64 {
-	rng := rand.New(rand.NewSource(seed))
-	results := make([][]float64, config.Iterations)
-	width := 2 * config.Size
-	cols, rows := width, width
-	if config.Divider == 0 {
-		rows = int(math.Ceil(math.Log2(float64(width))))
-	} else {
-		rows /= config.Divider
-	}
-	for iteration := range config.Iterations {
-		a, b := NewMatrix(cols, rows, make([]float32, cols*rows)...),
-			NewMatrix(cols, rows, make([]float32, cols*rows)...)
-		index := 0
-		for range a.Rows {
-			for range a.Cols {
-				a.Data[index] = float32(rng.NormFloat64())
-				b.Data[index] = float32(rng.NormFloat64())
-				index++
-			}
-		}
-		aa := a.Softmax(1)
-		bb := b.Softmax(1)
-		graph := pagerank.NewGraph()
-		x := NewMatrix(cols, len(vectors), make([]float32, cols*len(vectors))...)
-		y := NewMatrix(cols, len(vectors), make([]float32, cols*len(vectors))...)
-		for i := range vectors {
-			for ii, value := range vectors[i].Vector {
-				if value < 0 {
-					x.Data[i*cols+config.Size+ii] = -value
-					continue
-				}
-				x.Data[i*cols+ii] = value
-			}
-		}
-		for i := range vectors {
-			for ii, value := range vectors[i].Vector {
-				if value < 0 {
-					y.Data[i*cols+config.Size+ii] = -value
-					continue
-				}
-				y.Data[i*cols+ii] = value
-			}
-		}
-
-		xx := aa.MulT(x).Unit()
-		yy := bb.MulT(y).Unit()
-		cs := yy.MulT(xx)
-		if len(mutate) == 1 {
-			mutate[0](&cs)
-		}
-		for i := range cs.Rows {
-			for ii := range cs.Cols {
-				cs := cs.Data[i*cs.Cols+ii]
-				if cs < 0 {
-					cs = -cs
-				}
-				graph.Link(uint32(i), uint32(ii), float64(cs))
-			}
-		}
-
-		result := make([]float64, len(vectors))
-		graph.Rank(1.0, 1e-3, func(node uint32, rank float64) {
-			result[node] = rank
-		})
-		results[iteration] = result
+// parts returns the parts of the morpheus pipeline with their defaults filled in
+func (c Config) parts() (Projection, Similarity, EdgeFilter, Ranker) {
+	projection := c.Projection
+	if projection == nil {
+		projection = SoftmaxProjection{}
 	}
-	for _, result := range results {
-		for i, value := range result {
-			vectors[i].Avg += value
-		}
+	similarity := c.Similarity
+	if similarity == nil {
+		similarity = Cosine{}
 	}
-	for i, value := range vectors {
-		vectors[i].Avg = value.Avg / float64(config.Iterations)
+	filter := c.Filter
+	if filter == nil {
+		filter = AbsFilter{}
 	}
-
-	for _, result := range results {
-		for i, value := range result {
-			diff := value - vectors[i].Avg
-			vectors[i].Stddev += diff * diff
+	if len(c.Constraints) > 0 {
+		filter = constrained{
+			constraints: c.Constraints,
+			filter:      filter,
 		}
 	}
-	for i, value := range vectors {
-		vectors[i].Stddev = math.Sqrt(value.Stddev / float64(config.Iterations))
-	}
-
-	cov := make([][]float64, len(vectors))
-	for i := range cov {
-		cov[i] = make([]float64, len(vectors))
-	}
-	for _, measures := range results {
-		for i, v := range measures {
-			for ii, vv := range measures {
-				diff1 := vectors[i].Avg - v
-				diff2 := vectors[ii].Avg - vv
-				cov[i][ii] += diff1 * diff2
-			}
-		}
-	}
-	if len(results) > 0 {
-		for i := range cov {
-			for ii := range cov[i] {
-				cov[i][ii] = cov[i][ii] / float64(len(results))
-			}
-		}
+	ranker := c.Ranker
+	if ranker == nil {
+		ranker = GraphRanker{}
 	}
-	return cov
+	return projection, similarity, filter, ranker
 }
 
-func MorpheusGramSchmidt[T any](seed int64, config Config, vectors []*Vector[T], mutate ...func(cs *Matrix[float32])) [][]float64 {
-	rng := rand.New(rand.NewSource(seed))
-	results := make([][]float64, config.Iterations)
-	width := config.Size
+// shape returns the number of columns and rows of the projection matrices
+func (c Config) shape(projection Projection) (int, int) {
+	width := projection.Width(c.Size)
 	cols, rows := width, width
-	if config.Divider == 0 {
+	if c.Divider == 0 {
 		rows = int(math.Ceil(math.Log2(float64(width))))
 	} else {
-		rows /= config.Divider
+		rows /= c.Divider
 	}
-	for iteration := range config.Iterations {
-		a, b := NewMatrix(cols, rows, make([]float32, cols*rows)...),
-			NewMatrix(cols, rows, make([]float32, cols*rows)...)
-		index := 0
-		for range a.Rows {
-			for range a.Cols {
-				a.Data[index] = float32(rng.NormFloat64())
-				b.Data[index] = float32(rng.NormFloat64())
-				index++
-			}
-		}
-		aa := a.GramSchmidt().T()
-		bb := b.GramSchmidt().T()
-		x := NewMatrix(cols, len(vectors), make([]float32, cols*len(vectors))...)
-		y := NewMatrix(cols, len(vectors), make([]float32, cols*len(vectors))...)
-		for i := range vectors {
-			for ii, value := range vectors[i].Vector {
-				x.Data[i*cols+ii] = value
-			}
-		}
-		for i := range vectors {
-			for ii, value := range vectors[i].Vector {
-				y.Data[i*cols+ii] = value
-			}
-		}
+	return cols, rows
+}
 
-		xx := aa.MulT(x).Unit()
-		yy := bb.MulT(y).Unit()
-		cs := yy.MulT(xx)
-		if len(mutate) == 1 {
-			mutate[0](&cs)
-		}
-		accuracy := 256
-		if config.Accuracy > 0 {
-			accuracy = config.Accuracy
-		}
-		result := PageRank(1.0, accuracy, rng.Uint32(), cs)
-		r := make([]float64, len(result.Data))
-		for key, value := range result.Data {
-			r[key] = float64(value)
-		}
-		results[iteration] = r
-	}
-	for _, result := range results {
-		for i, value := range result {
-			vectors[i].Avg += value
-		}
-	}
-	for i, value := range vectors {
-		vectors[i].Avg = value.Avg / float64(config.Iterations)
+// encode encodes the vectors into the projection input and returns the size of the projection matrices
+func encode[T any](config Config, projection Projection, vectors []*Vector[T]) (int, int, Matrix[float32]) {
+	cols, rows := config.shape(projection)
+	x := NewMatrix(cols, len(vectors), make([]float32, cols*len(vectors))...)
+	for i := range vectors {
+		projection.Encode(config.Size, x.Data[i*cols:(i+1)*cols], vectors[i].Vector)
 	}
+	return cols, rows, x
+}
 
-	for _, result := range results {
-		for i, value := range result {
-			diff := value - vectors[i].Avg
-			vectors[i].Stddev += diff * diff
-		}
-	}
-	for i, value := range vectors {
-		vectors[i].Stddev = math.Sqrt(value.Stddev / float64(config.Iterations))
-	}
+// Result is the result of the morpheus algorithm
+type Result struct {
+	// Avg is the average rank of each vector
+	Avg []float64
+	// Stddev is the standard deviation of the rank of each vector
+	Stddev []float64
+	// Cov is the covariance of the ranks
+	Cov [][]float64
+	// Ranks are the ranks of each iteration
+	Ranks [][]float64
+	// Sparse is the covariance of the ranks of the linked vectors in sparse mode
+	Sparse CSR[float64]
+	// Embedding is the spectral embedding of the covariance if config.Embedding is set
+	Embedding [][]float64
+	// Iterations is the number of iterations that were run
+	Iterations int
+	// Delta is the change of the statistics made by the last batch of an adaptive run
+	Delta Delta
+}
 
-	cov := make([][]float64, len(vectors))
-	for i := range cov {
-		cov[i] = make([]float64, len(vectors))
-	}
-	for _, measures := range results {
-		for i, v := range measures {
-			for ii, vv := range measures {
-				diff1 := vectors[i].Avg - v
-				diff2 := vectors[ii].Avg - vv
-				cov[i][ii] += diff1 * diff2
-			}
-		}
-	}
-	if len(results) > 0 {
-		for i := range cov {
-			for ii := range cov[i] {
-				cov[i][ii] = cov[i][ii] / float64(len(results))
-			}
+// Delta is the largest absolute change of each statistic between two batches of iterations
+type Delta struct {
+	Avg    float64
+	Stddev float64
+	Cov    float64
+}
+
+// Converged returns true if every change is at most tolerance
+func (d Delta) Converged(tolerance float64) bool {
+	return d.Avg <= tolerance && d.Stddev <= tolerance && d.Cov <= tolerance
+}
+
+// Morpheus ranks the projected similarity graph of the vectors config.Iterations times
+// and returns the statistics of the ranks, the vectors are not modified. The iterations
+// run in parallel, each with its own seed drawn from seed, so mutate must be safe to call
+// concurrently
+func Morpheus[T any](seed int64, config Config, vectors []*Vector[T], mutate ...func(cs *Matrix[float32])) Result {
+	projection, similarity, filter, ranker := config.parts()
+	cols, rows, x := encode(config, projection, vectors)
+	process := func(iteration int, rng *rand.Rand) []float64 {
+		aa, bb := projection.Project(rng, cols, rows)
+		cs := similarity.Similarity(aa.MulT(x), bb.MulT(x))
+		if len(mutate) == 1 {
+			mutate[0](&cs)
 		}
+		return ranker.Rank(rng, cs, filter)
 	}
-	return cov
+	result := converge(rand.New(rand.NewSource(seed)), config,
+		sequential(projection, similarity, filter, ranker), process, newAccumulator(len(vectors)))
+	return spectral(config, result)
 }
 
-func Morpheus2[T any](seed int64, config Config, vectors []*Vector[T], g map[string]map[string]uint64) [][]float64 {
-	rng := rand.New(rand.NewSource(seed))
-	results := make([][]float64, config.Iterations)
-	width := 2 * config.Size
-	cols, rows := width, width
-	if config.Divider == 0 {
-		rows = int(math.Ceil(math.Log2(float64(width))))
-	} else {
-		rows /= config.Divider
-	}
-	for iteration := range config.Iterations {
-		a, b := NewMatrix(cols, rows, make([]float32, cols*rows)...),
-			NewMatrix(cols, rows, make([]float32, cols*rows)...)
-		index := 0
-		for range a.Rows {
-			for range a.Cols {
-				a.Data[index] = float32(rng.NormFloat64())
-				b.Data[index] = float32(rng.NormFloat64())
-				index++
-			}
+// converge runs batches of config.Iterations iterations with seeds drawn from rng and adds their ranks
+// to the accumulator, a run that isn't adaptive stops after the first batch and an adaptive run stops
+// when the statistics have converged or config.MaxIterations is reached. process is called with the
+// index of the iteration in the run
+func converge(rng *rand.Rand, config Config, sequential bool,
+	process func(iteration int, rng *rand.Rand) []float64, stats *accumulator) Result {
+	if config.Convergence <= 0 && config.Iterations <= 0 {
+		panic(ErrIterations)
+	}
+	maximum := config.MaxIterations
+	if maximum <= 0 {
+		maximum = 1024
+	}
+	var previous Result
+	for {
+		batch := config.Iterations
+		if config.Convergence > 0 {
+			batch = max(min(batch, maximum-stats.n), 1)
+		}
+		seeds := iterationSeeds(rng, batch)
+		ranks := make([][]float64, batch)
+		iterate(batch, sequential, func(iteration int) {
+			ranks[iteration] = process(stats.n+iteration, rand.New(rand.NewSource(seeds[iteration])))
+		})
+		for _, ranks := range ranks {
+			stats.add(ranks)
 		}
-		aa := a.Softmax(1)
-		bb := b.Softmax(1)
-		graph := pagerank.NewGraph()
-		x := NewMatrix(cols, len(vectors), make([]float32, cols*len(vectors))...)
-		y := NewMatrix(cols, len(vectors), make([]float32, cols*len(vectors))...)
-		for i := range vectors {
-			for ii, value := range vectors[i].Vector {
-				if value < 0 {
-					x.Data[i*cols+config.Size+ii] = -value
-					continue
-				}
-				x.Data[i*cols+ii] = value
-			}
+		r := stats.result()
+		if config.Convergence <= 0 {
+			return r
 		}
-		for i := range vectors {
-			for ii, value := range vectors[i].Vector {
-				if value < 0 {
-					y.Data[i*cols+config.Size+ii] = -value
-					continue
-				}
-				y.Data[i*cols+ii] = value
+		if previous.Avg != nil {
+			r.Delta = delta(previous, r)
+			if r.Delta.Converged(config.Convergence) {
+				return r
 			}
 		}
-
-		xx := aa.MulT(x).Unit()
-		yy := bb.MulT(y).Unit()
-		cs := yy.MulT(xx)
-		for i := range cs.Rows {
-			for ii := range cs.Cols {
-				from := g[vectors[i].Word]
-				if from != nil {
-					to := from[vectors[ii].Word]
-					if to > 0 {
-						graph.Link(uint32(i), uint32(ii), float64(cs.Data[i*cs.Cols+ii]))
-					}
-				}
-			}
+		if stats.n >= maximum {
+			return r
 		}
+		previous = r
+	}
+}
 
-		result := make([]float64, len(vectors))
-		graph.Rank(1.0, 1e-3, func(node uint32, rank float64) {
-			result[node] = rank
-		})
-		results[iteration] = result
+// delta computes the largest change of each statistic from a to b
+func delta(a, b Result) Delta {
+	d := Delta{}
+	for i := range a.Avg {
+		d.Avg = max(d.Avg, math.Abs(a.Avg[i]-b.Avg[i]))
+		d.Stddev = max(d.Stddev, math.Abs(a.Stddev[i]-b.Stddev[i]))
 	}
-	for _, result := range results {
-		for i, value := range result {
-			vectors[i].Avg += value
+	for i := range a.Cov {
+		for ii := range a.Cov[i] {
+			d.Cov = max(d.Cov, math.Abs(a.Cov[i][ii]-b.Cov[i][ii]))
 		}
 	}
-	for i, value := range vectors {
-		vectors[i].Avg = value.Avg / float64(config.Iterations)
+	for i := range a.Sparse.Data {
+		d.Cov = max(d.Cov, math.Abs(a.Sparse.Data[i]-b.Sparse.Data[i]))
 	}
+	return d
+}
 
-	for _, result := range results {
-		for i, value := range result {
-			diff := value - vectors[i].Avg
-			vectors[i].Stddev += diff * diff
-		}
-	}
-	for i, value := range vectors {
-		vectors[i].Stddev = math.Sqrt(value.Stddev / float64(config.Iterations))
+// iterationSeeds draws the seed of each iteration
+func iterationSeeds(rng *rand.Rand, iterations int) []int64 {
+	seeds := make([]int64, iterations)
+	for i := range seeds {
+		seeds[i] = rng.Int63()
 	}
+	return seeds
+}
 
-	cov := make([][]float64, len(vectors))
-	for i := range cov {
-		cov[i] = make([]float64, len(vectors))
-	}
-	for _, measures := range results {
-		for i, v := range measures {
-			for ii, vv := range measures {
-				diff1 := vectors[i].Avg - v
-				diff2 := vectors[ii].Avg - vv
-				cov[i][ii] += diff1 * diff2
-			}
-		}
+// MorpheusGramSchmidt is morpheus with orthonormal projections and by default the counting pagerank
+func MorpheusGramSchmidt[T any](seed int64, config Config, vectors []*Vector[T], mutate ...func(cs *Matrix[float32])) Result {
+	config.Projection = GramSchmidtProjection{}
+	config.Filter = SignedFilter{}
+	if config.Ranker == nil {
+		config.Ranker = CountingRanker{Accuracy: config.Accuracy}
+	}
+	return Morpheus(seed, config, vectors, mutate...)
+}
+
+// Morpheus2 is morpheus with the similarity graph masked by the word graph g, the signed edges
+// move rank by their magnitude as described in CSR.PageRank unless config.Ranker is signed
+func Morpheus2[T any](seed int64, config Config, vectors []*Vector[T], g map[string]map[string]uint64) Result {
+	config.Projection = SoftmaxProjection{}
+	config.Filter = SignedFilter{}
+	config.Constraints = append([]EdgeConstraint{NewWordConstraint(vectors, g)}, config.Constraints...)
+	return Morpheus(seed, config, vectors)
+}
+
+// Morpheus3 is morpheus with a single fixed projection, noise added to the edges
+// and one graph accumulated over all iterations, the projection, the noise and the iterations
+// are seeded from seed
+func Morpheus3[T any](seed int64, config Config, vectors []*Vector[T]) Result {
+	rng := rand.New(rand.NewSource(seed))
+	config.Projection = &FixedProjection{
+		Projection: SoftmaxProjection{},
+		Seed:       rng.Int63(),
 	}
-	if len(results) > 0 {
-		for i := range cov {
-			for ii := range cov[i] {
-				cov[i][ii] = cov[i][ii] / float64(len(results))
-			}
-		}
+	config.Filter = &NoiseFilter{
+		RNG:   NewRNG(rng.Int63()),
+		Scale: .01,
 	}
-	return cov
+	config.Ranker = &AccumulatingRanker{}
+	return Morpheus(rng.Int63(), config, vectors)
 }
 
-func Morpheus3[T any](seed int64, config Config, vectors []*Vector[T]) [][]float64 {
-	rng := RNG(1)
-	rng2 := rand.New(rand.NewSource(1))
-	results := make([][]float64, config.Iterations)
-	width := 2 * config.Size
-	cols, rows := width, width
-	if config.Divider == 0 {
-		rows = int(math.Ceil(math.Log2(float64(width))))
-	} else {
-		rows /= config.Divider
+// MorpheusSparse is morpheus on a graph that links each vector to itself and its config.Neighbors
+// nearest cosine neighbors, the edges are weighted with the kernel similarity of the projections,
+// the graph is ranked with config.Ranker, which must be a GraphRanker, and the covariance is only
+// computed for the linked vectors. The search and the iterations are seeded from seed
+func MorpheusSparse[T any](seed int64, config Config, vectors []*Vector[T]) Result {
+	projection, similarity, filter, ranker := config.parts()
+	kernel, ok := similarity.(Kernel)
+	if !ok {
+		panic(ErrKernel)
+	}
+	graphRanker, ok := ranker.(GraphRanker)
+	if !ok {
+		panic(ErrRanker)
 	}
-	a, b := NewMatrix(cols, rows, make([]float32, cols*rows)...),
-		NewMatrix(cols, rows, make([]float32, cols*rows)...)
-	index := 0
-	for range a.Rows {
-		for range a.Cols {
-			a.Data[index] = float32(rng2.NormFloat64())
-			b.Data[index] = float32(rng2.NormFloat64())
-			index++
-		}
+	rng := rand.New(rand.NewSource(seed))
+	searchSeed, runSeed := rng.Int63(), rng.Int63()
+	search := config.Search
+	if search == nil {
+		search = BlockedSearch{}
 	}
-	aa := a.Softmax(1)
-	bb := b.Softmax(1)
-	graph := pagerank.NewGraph()
-	x := NewMatrix(cols, len(vectors), make([]float32, cols*len(vectors))...)
-	y := NewMatrix(cols, len(vectors), make([]float32, cols*len(vectors))...)
-	for i := range vectors {
-		for ii, value := range vectors[i].Vector {
-			if value < 0 {
-				x.Data[i*cols+config.Size+ii] = -value
-				continue
-			}
-			x.Data[i*cols+ii] = value
+	cols, rows, x := encode(config, projection, vectors)
+
+	neighbors := make([][]int, len(vectors))
+	k := min(config.Neighbors, len(vectors)-1)
+	if k > 0 {
+		found := search.Search(rand.New(rand.NewSource(searchSeed)), x.Unit(), k)
+		for i := range neighbors {
+			neighbors[i] = append([]int{i}, found[i]...)
 		}
-	}
-	for i := range vectors {
-		for ii, value := range vectors[i].Vector {
-			if value < 0 {
-				y.Data[i*cols+config.Size+ii] = -value
-				continue
+	} else {
+		for i := range neighbors {
+			neighbors[i] = []int{i}
+		}
+	}
+	pattern := NewCSR[float64](len(vectors), neighbors)
+	process := func(iteration int, rng *rand.Rand) []float64 {
+		graph := pattern
+		graph.Data = make([]float64, len(pattern.Data))
+		aa, bb := projection.Project(rng, cols, rows)
+		xx := kernel.Normalize(aa.MulT(x))
+		yy := kernel.Normalize(bb.MulT(x))
+		for i := range graph.Rows {
+			indices, data := graph.Row(i)
+			x := xx.Data[i*xx.Cols : (i+1)*xx.Cols]
+			for ii, j := range indices {
+				weight, ok := filter.Filter(i, j, kernel.Pair(x, yy.Data[j*yy.Cols:(j+1)*yy.Cols]))
+				if !ok {
+					weight = 0
+				}
+				data[ii] = weight
 			}
-			y.Data[i*cols+ii] = value
 		}
+		return graphRanker.rank(graph)
 	}
+	result := converge(rand.New(rand.NewSource(runSeed)), config, sequential(projection, kernel, filter), process,
+		newSparseAccumulator(neighbors))
+	return spectral(config, result)
+}
 
-	xx := aa.MulT(x).Unit()
-	yy := bb.MulT(y).Unit()
-	cs := yy.MulT(xx)
-	for iteration := range config.Iterations {
-		for i := range cs.Rows {
-			for ii := range cs.Cols {
-				graph.Link(uint32(i), uint32(ii), float64(cs.Data[i*cs.Cols+ii])+.01*float64(rng.Float32()))
-			}
-		}
+// accumulator keeps the running average, variance and covariance of the ranks with welford's algorithm,
+// so adding an iteration doesn't revisit the previous ones
+type accumulator struct {
+	n      int
+	avg    []float64
+	m2     []float64
+	diff   []float64
+	cov    [][]float64
+	sparse CSR[float64]
+	ranks  [][]float64
+}
 
-		result := make([]float64, len(vectors))
-		graph.Rank(1.0, 1e-3, func(node uint32, rank float64) {
-			result[node] = rank
-		})
-		results[iteration] = result
-	}
-	for _, result := range results {
-		for i, value := range result {
-			vectors[i].Avg += value
-		}
-	}
-	for i, value := range vectors {
-		vectors[i].Avg = value.Avg / float64(config.Iterations)
+// newAccumulator accumulates the statistics and the dense covariance of the ranks of n vectors
+func newAccumulator(n int) *accumulator {
+	a := accumulator{
+		avg:  make([]float64, n),
+		m2:   make([]float64, n),
+		diff: make([]float64, n),
+		cov:  make([][]float64, n),
+	}
+	for i := range a.cov {
+		a.cov[i] = make([]float64, n)
+	}
+	return &a
+}
+
+// newSparseAccumulator accumulates the statistics and the covariance of the ranks of the neighbors
+func newSparseAccumulator(neighbors [][]int) *accumulator {
+	n := len(neighbors)
+	return &accumulator{
+		avg:    make([]float64, n),
+		m2:     make([]float64, n),
+		diff:   make([]float64, n),
+		sparse: NewCSR[float64](n, neighbors),
 	}
+}
 
-	for _, result := range results {
-		for i, value := range result {
-			diff := value - vectors[i].Avg
-			vectors[i].Stddev += diff * diff
+// add adds the ranks of one iteration
+func (a *accumulator) add(ranks []float64) {
+	a.n++
+	a.ranks = append(a.ranks, ranks)
+	n := float64(a.n)
+	for i, value := range ranks {
+		diff := value - a.avg[i]
+		a.diff[i] = diff
+		a.avg[i] += diff / n
+		a.m2[i] += diff * (value - a.avg[i])
+	}
+	// (x_i - old avg_i)(x_j - new avg_j) is diff_i diff_j (n-1)/n, which keeps the covariance symmetric
+	scale := (n - 1) / n
+	for i, row := range a.cov {
+		diff := a.diff[i]
+		for ii, value := range a.diff {
+			row[ii] += diff * value * scale
+		}
+	}
+	for i := range a.sparse.Rows {
+		indices, data := a.sparse.Row(i)
+		diff := a.diff[i]
+		for ii, j := range indices {
+			data[ii] += diff * a.diff[j] * scale
 		}
 	}
-	for i, value := range vectors {
-		vectors[i].Stddev = math.Sqrt(value.Stddev / float64(config.Iterations))
-	}
+}
 
-	cov := make([][]float64, len(vectors))
-	for i := range cov {
-		cov[i] = make([]float64, len(vectors))
-	}
-	for _, measures := range results {
-		for i, v := range measures {
-			for ii, vv := range measures {
-				diff1 := vectors[i].Avg - v
-				diff2 := vectors[ii].Avg - vv
-				cov[i][ii] += diff1 * diff2
+// result computes the statistics of the ranks added so far
+func (a *accumulator) result() Result {
+	if a.n == 0 {
+		panic(ErrIterations)
+	}
+	n := float64(a.n)
+	r := Result{
+		Avg:        append([]float64{}, a.avg...),
+		Stddev:     make([]float64, len(a.m2)),
+		Ranks:      a.ranks,
+		Iterations: a.n,
+	}
+	for i, value := range a.m2 {
+		r.Stddev[i] = math.Sqrt(value / n)
+	}
+	if a.cov != nil {
+		r.Cov = make([][]float64, len(a.cov))
+		for i, row := range a.cov {
+			r.Cov[i] = make([]float64, len(row))
+			for ii, value := range row {
+				r.Cov[i][ii] = value / n
 			}
 		}
 	}
-	if len(results) > 0 {
-		for i := range cov {
-			for ii := range cov[i] {
-				cov[i][ii] = cov[i][ii] / float64(len(results))
-			}
+	if a.sparse.Rows > 0 {
+		r.Sparse = a.sparse
+		r.Sparse.Data = make([]float64, len(a.sparse.Data))
+		for i, value := range a.sparse.Data {
+			r.Sparse.Data[i] = value / n
 		}
 	}
-	return cov
+	return r
 }
 
+// statistics computes the average, standard deviation and covariance of the ranks of n vectors
+func statistics(results [][]float64, n int) Result {
+	a := newAccumulator(n)
+	for _, ranks := range results {
+		a.add(ranks)
+	}
+	return a.result()
+}
+
+// MorpheusMarkov learns a markov model of the walks on the cosine similarity graph of the vectors
+// constrained by config.Constraints and returns its conditional or flow matrix as selected by config.Markov
 func MorpheusMarkov[T any, F Float](seed int64, config Config, vectors []*Vector[T]) Matrix[F] {
 	rng := rand.New(rand.NewSource(seed))
 	width := config.Size
@@ -470,8 +454,11 @@ func MorpheusMarkov[T any, F Float](seed int64, config Config, vectors []*Vector
 	xx := x.Unit()
 	yy := y.Unit()
 	adj := yy.MulT(xx)
+	if len(config.Constraints) > 0 {
+		constrainMatrix(adj, config.Constraints)
+	}
 	/*for i := range adj.Cols {
 		adj.Data[i*adj.Cols+i] = 0
 	}*/
-	return PageRankMarkov(.85, 1024, rng.Uint32(), adj)
+	return learnMarkov(false, .85, 1024, rng.Uint32(), adj).Output(config.Markov)
 }
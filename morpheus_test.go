@@ -5,14 +5,31 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
 	"testing"
 
 	"github.com/alixaxel/pagerank"
 )
 
+// normal draws n vectors of size gaussian values
+func normal[T any](rng *rand.Rand, n, size int) []*Vector[T] {
+	vectors := make([]*Vector[T], n)
+	for i := range vectors {
+		vector := Vector[T]{}
+		for range size {
+			vector.Vector = append(vector.Vector, float32(rng.NormFloat64()))
+		}
+		vectors[i] = &vector
+	}
+	return vectors
+}
+
 func TestPageRank(t *testing.T) {
 	graph := pagerank.NewGraph()
 
@@ -45,8 +62,59 @@ func TestPageRankNegative(t *testing.T) {
 	adj.Data[1*4+2] = 3.0
 	adj.Data[1*4+3] = -4.0
 	adj.Data[2*4+0] = 5.0
-	p := PageRank(.85, 32, 1, adj)
+	expected := must(FromDense(adj).SignedPageRank(.85, 1e-12, nil))
+	p := PageRank(.85, 4096, 1, adj)
 	t.Log(p.Data)
+	sum := 0.0
+	for i, value := range p.Data {
+		// the walks are short so they are biased towards the node they start from
+		if math.Abs(value-expected[i]) > .025 {
+			t.Fatalf("%d: %f != %f", i, value, expected[i])
+		}
+		sum += math.Abs(value)
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		t.Fatalf("absolute sum %f != 1", sum)
+	}
+	// node 3 is only reached through a negative edge
+	if p.Data[3] >= 0 || expected[3] >= 0 {
+		t.Fatalf("%f %f should be negative", p.Data[3], expected[3])
+	}
+}
+
+func TestPageRankPersonalized(t *testing.T) {
+	adj := [][]float64{
+		{0, 1, 2, 0, 0},
+		{0, 0, 3, 4, 0},
+		{5, 0, 0, 0, 1},
+		{0, 0, 0, 0, 0},
+		{1, 1, 0, 1, 0},
+	}
+	rank := func(a float32, teleport []float64) []float64 {
+		m := NewMatrix(5, 5, make([]float64, 5*5)...)
+		for i := range adj {
+			copy(m.Data[i*5:(i+1)*5], adj[i])
+		}
+		expected := must(FromDense(m).SignedPageRank(float64(a), 1e-12, teleport))
+		p := PageRank(a, 4096, 1, m, teleport).Data
+		for i, value := range p {
+			if math.Abs(value-expected[i]) > .025 {
+				t.Fatalf("%d: %f != %f", i, value, expected[i])
+			}
+		}
+		return p
+	}
+	// without damping every walk teleports to node 1 and takes one step
+	for i, value := range rank(0, []float64{0, 1, 0, 0, 0}) {
+		if expected := adj[1][i] / 7; math.Abs(value-expected) > .02 {
+			t.Fatalf("%d: %f != %f", i, value, expected)
+		}
+	}
+	uniform := rank(.85, nil)
+	personalized := rank(.85, []float64{0, 0, 0, 1, 3})
+	if personalized[3] <= uniform[3] || personalized[4] <= uniform[4] {
+		t.Fatalf("teleport did not bias the rank: %v %v", uniform, personalized)
+	}
 }
 
 func TestPageRankMarkov(t *testing.T) {
@@ -58,6 +126,105 @@ func TestPageRankMarkov(t *testing.T) {
 	adj.Data[2*4+0] = 5.0
 	p := PageRankMarkov(.85, 33, 1, adj)
 	t.Log(p.Data)
+	for i := range p.Rows {
+		sum := 0.0
+		for _, value := range p.Data[i*p.Cols : (i+1)*p.Cols] {
+			sum += value
+		}
+		if math.Abs(sum-1) > 1e-9 {
+			t.Fatalf("row %d sums to %f", i, sum)
+		}
+	}
+}
+
+func TestRNG(t *testing.T) {
+	rng := NewRNG(1)
+	for range 1024 {
+		state := uint32(rng)
+		for range 32 {
+			state = clock(state)
+		}
+		if next := rng.Next(); next != state {
+			t.Fatalf("%d != %d", next, state)
+		}
+	}
+	if zero := NewRNG(0); zero == 0 {
+		t.Fatal("the zero state never changes")
+	}
+	if jump := advance(LFSRPeriod); jump.apply(uint32(rng)) != uint32(rng) {
+		t.Fatal("the period is wrong")
+	}
+
+	// the streams are evenly spaced over the period
+	const n = 1 << 20
+	streams, state := rng.Split(n), uint32(rng)
+	for i := range 4 {
+		for range LFSRPeriod / (n + 1) {
+			state = clock(state)
+		}
+		if uint32(streams[i]) != state {
+			t.Fatalf("stream %d starts at %d not %d", i, streams[i], state)
+		}
+	}
+
+	// consecutive values are uncorrelated
+	var source rand.Source64 = &rng
+	sum, product, previous := 0.0, 0.0, rand.New(source).Float64()-.5
+	for range 1 << 16 {
+		value := rng.Float32() - .5
+		sum += float64(value)
+		product += float64(value) * previous
+		previous = float64(value)
+	}
+	if mean, correlation := sum/(1<<16), 12*product/(1<<16); math.Abs(mean) > .01 || math.Abs(correlation) > .02 {
+		t.Fatalf("mean %f correlation %f", mean, correlation)
+	}
+}
+
+// mulT is the reference unblocked MulT
+func mulT[T Float](m, n Matrix[T]) Matrix[T] {
+	o := NewMatrix[T](m.Rows, n.Rows)
+	for i := range n.Rows {
+		for j := range m.Rows {
+			o.Data = append(o.Data, dot(m.Data[j*m.Cols:(j+1)*m.Cols], n.Data[i*n.Cols:(i+1)*n.Cols]))
+		}
+	}
+	return o
+}
+
+func testMulT[T Float](t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	random := func(cols, rows int) Matrix[T] {
+		m := NewMatrix(cols, rows, make([]T, cols*rows)...)
+		for i := range m.Data {
+			m.Data[i] = T(rng.NormFloat64())
+		}
+		return m
+	}
+	// the shapes are serial, parallel and not a multiple of the block
+	for _, shape := range [][3]int{{8, 3, 5}, {96, 130, 70}, {256, 64, 200}, {8, 1, 300}} {
+		m, n := random(shape[0], shape[1]), random(shape[0], shape[2])
+		expected := mulT(m, n)
+		buffer := make([]T, 0, shape[1]*shape[2])
+		for _, o := range []Matrix[T]{m.MulT(n), m.MulT(n, buffer), m.MulT(n, nil)} {
+			if o.Cols != expected.Cols || o.Rows != expected.Rows {
+				t.Fatalf("%v: %dx%d != %dx%d", shape, o.Cols, o.Rows, expected.Cols, expected.Rows)
+			}
+			for i, value := range o.Data {
+				if value != expected.Data[i] {
+					t.Fatalf("%v %d: %f != %f", shape, i, value, expected.Data[i])
+				}
+			}
+		}
+		if &m.MulT(n, buffer).Data[0] != &buffer[:1][0] {
+			t.Fatalf("%v: the buffer was not used", shape)
+		}
+	}
+}
+
+func TestMulT(t *testing.T) {
+	testMulT[float32](t)
+	testMulT[float64](t)
 }
 
 func TestGramSchmidt(t *testing.T) {
@@ -83,6 +250,466 @@ func TestGramSchmidt(t *testing.T) {
 	}
 }
 
+func TestEigen(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	const n = 8
+	m := NewMatrix(n, n, make([]float64, n*n)...)
+	for i := range n {
+		for ii := i; ii < n; ii++ {
+			value := rng.NormFloat64()
+			m.Data[i*n+ii], m.Data[ii*n+i] = value, value
+		}
+	}
+	values, vectors := m.Eigen()
+	for i := range n {
+		if i > 0 && values[i] > values[i-1] {
+			t.Fatalf("eigenvalues are not sorted: %v", values)
+		}
+		v := vectors.Data[i*n : (i+1)*n]
+		for ii := range n {
+			av := dot(m.Data[ii*n:(ii+1)*n], v)
+			if diff := math.Abs(av - values[i]*v[ii]); diff > 1e-9 {
+				t.Fatalf("eigenvector %d is off by %g", i, diff)
+			}
+			expected := 0.0
+			if i == ii {
+				expected = 1
+			}
+			if diff := math.Abs(dot(v, vectors.Data[ii*n:(ii+1)*n]) - expected); diff > 1e-9 {
+				t.Fatalf("eigenvectors %d %d are not orthonormal: %g", i, ii, diff)
+			}
+		}
+	}
+}
+
+func TestMorpheusPipeline(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	type T struct{}
+	vectors := normal[T](rng, 8, 16)
+	config := Config{
+		Iterations: 8,
+		Size:       16,
+		Divider:    1,
+		Accuracy:   8,
+	}
+	a := MorpheusGramSchmidt(1, config, vectors).Cov
+	config.Projection = GramSchmidtProjection{}
+	config.Similarity = Cosine{}
+	config.Filter = SignedFilter{}
+	config.Ranker = CountingRanker{Accuracy: 8}
+	b := Morpheus(1, config, vectors).Cov
+	for i := range a {
+		for ii := range a[i] {
+			if a[i][ii] != b[i][ii] {
+				t.Fatalf("%d %d: %f != %f", i, ii, a[i][ii], b[i][ii])
+			}
+		}
+	}
+}
+
+// the golden values were captured from the code before the pipeline refactor, seeded so that
+// its only iteration draws the same projections as the first iteration of Morpheus with seed 1
+var (
+	goldenSoftmax = []float32{
+		0.9262283, 0.94598883, 0.95173025, 0.9435933, 0.93655115, 0.93006617,
+		0.9377496, 0.89941233, 0.9510665, 0.9607035, 0.9510011, 0.9337325,
+		0.93909013, 0.9424488, 0.9210493, 0.92186546, 0.93967044, 0.94983315,
+		0.91103363, 0.90739024, 0.9112093, 0.9119975, 0.918273, 0.9261067,
+		0.95824754, 0.9623093, 0.950084, 0.94183695, 0.96444595, 0.93388724,
+		0.90476716, 0.91019154, 0.9355351, 0.9441849, 0.94866705, 0.95852876,
+		0.97387636, 0.9208039, 0.9005938, 0.8580332, 0.94474125, 0.96144086,
+		0.9215121, 0.921568, 0.93439364, 0.92906666, 0.8888817, 0.93391657,
+		0.9318198, 0.9490352, 0.9464284, 0.9142922, 0.9207344, 0.9516157,
+		0.9087868, 0.91047347, 0.943285, 0.953377, 0.92575955, 0.9278467,
+		0.9352282, 0.91869885, 0.9273504, 0.9167174,
+	}
+	goldenGramSchmidt = []float32{
+		0.08251822, 0.03233894, -0.33545476, 0.010301799, 0.6321058, -0.25028306,
+		-0.5815413, 0.062455967, -0.006227914, -0.057204768, -0.083735555, -0.18785778,
+		-0.432087, -0.14620522, 0.1775824, -0.102092944, -0.18240525, -0.04084549,
+		-0.025837846, 0.39765263, 0.16394642, 0.058262054, 0.31532127, 0.090326786,
+		0.11518444, -0.446204, -0.10900313, -0.078724116, -0.107838884, -0.2329628,
+		0.12954128, -0.22534746, -0.16869964, -0.21159327, -0.20307481, -0.23171733,
+		-0.0076080486, -0.16049866, 0.13908133, -0.14588192, -0.12070877, -0.21736833,
+		0.37943238, 0.16366808, -0.06525357, 0.20443898, 0.51075125, -0.044779312,
+		-0.05326189, 0.15110251, 0.23312873, 0.37377837, -0.058569137, 0.20973486,
+		0.048836175, -0.06262702, -0.06370554, 0.15741783, 0.10407528, -0.37232786,
+		-0.7176812, 0.34261417, 0.48778713, 0.084533066,
+	}
+	goldenRanks = []float64{
+		0.12624410803376382, 0.12785818811192073, 0.1258364030273512, 0.12487881068853493,
+		0.1259932564852863, 0.1246973712030289, 0.12250695011782091, 0.12198491233229322,
+	}
+)
+
+func TestMorpheusGolden(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	type T struct{}
+	vectors := normal[T](rng, 8, 16)
+	config := Config{
+		Iterations: 1,
+		Size:       16,
+		Divider:    1,
+		Accuracy:   8,
+	}
+	golden := func(name string, expected []float32) func(cs *Matrix[float32]) {
+		return func(cs *Matrix[float32]) {
+			for i, value := range cs.Data {
+				if value != expected[i] {
+					t.Fatalf("%s %d: %f != %f", name, i, value, expected[i])
+				}
+			}
+		}
+	}
+	// the ranks were computed with the alixaxel pagerank, so they only agree to its tolerance
+	result := Morpheus(1, config, vectors, golden("softmax", goldenSoftmax))
+	for i, value := range result.Avg {
+		if diff := math.Abs(value - goldenRanks[i]); diff > 1e-3 {
+			t.Fatalf("rank %d: %f != %f", i, value, goldenRanks[i])
+		}
+	}
+	MorpheusGramSchmidt(1, config, vectors, golden("gramschmidt", goldenGramSchmidt))
+}
+
+func TestKernels(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	x := NewMatrix(8, 5, make([]float32, 8*5)...)
+	y := NewMatrix(8, 5, make([]float32, 8*5)...)
+	for i := range x.Data {
+		x.Data[i] = float32(rng.NormFloat64())
+		y.Data[i] = float32(rng.NormFloat64())
+	}
+	for name, kernel := range Kernels {
+		cs := kernel.Similarity(x, y)
+		xx, yy := kernel.Normalize(x), kernel.Normalize(y)
+		for i := range x.Rows {
+			for ii := range y.Rows {
+				expected := kernel.Pair(xx.Data[i*xx.Cols:(i+1)*xx.Cols], yy.Data[ii*yy.Cols:(ii+1)*yy.Cols])
+				if diff := math.Abs(float64(cs.Data[i*cs.Cols+ii] - expected)); diff > 1e-5 {
+					t.Fatalf("%s %d %d: %f != %f", name, i, ii, cs.Data[i*cs.Cols+ii], expected)
+				}
+				if name == "rectified" && expected < 0 {
+					t.Fatalf("%s %d %d: %f < 0", name, i, ii, expected)
+				}
+			}
+		}
+	}
+}
+
+func TestMorpheusConcurrent(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	type T struct{}
+	vectors := normal[T](rng, 8, 16)
+	config := Config{
+		Iterations: 8,
+		Size:       16,
+		Divider:    1,
+		Accuracy:   8,
+	}
+	expected := MorpheusGramSchmidt(1, config, vectors)
+	if len(expected.Ranks) != config.Iterations {
+		t.Fatalf("%d != %d", len(expected.Ranks), config.Iterations)
+	}
+	results := make([]Result, 4)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Go(func() { results[i] = MorpheusGramSchmidt(1, config, vectors) })
+	}
+	wg.Wait()
+	for _, result := range results {
+		for i := range expected.Avg {
+			if result.Avg[i] != expected.Avg[i] {
+				t.Fatalf("avg %d: %f != %f", i, result.Avg[i], expected.Avg[i])
+			}
+			if result.Stddev[i] != expected.Stddev[i] {
+				t.Fatalf("stddev %d: %f != %f", i, result.Stddev[i], expected.Stddev[i])
+			}
+			for ii := range expected.Cov[i] {
+				if result.Cov[i][ii] != expected.Cov[i][ii] {
+					t.Fatalf("cov %d %d: %f != %f", i, ii, result.Cov[i][ii], expected.Cov[i][ii])
+				}
+			}
+		}
+	}
+}
+
+func TestMorpheusGOMAXPROCS(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	type T struct{}
+	vectors := normal[T](rng, 16, 16)
+	config := Config{
+		Iterations: 16,
+		Size:       16,
+		Divider:    1,
+		Accuracy:   8,
+		Neighbors:  4,
+	}
+	run := func(procs int) (Result, Result) {
+		defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(procs))
+		return MorpheusGramSchmidt(1, config, vectors), MorpheusSparse(1, config, vectors)
+	}
+	a, aa := run(1)
+	b, bb := run(8)
+	for i := range vectors {
+		if a.Avg[i] != b.Avg[i] {
+			t.Fatalf("avg %d: %f != %f", i, a.Avg[i], b.Avg[i])
+		}
+		if aa.Avg[i] != bb.Avg[i] {
+			t.Fatalf("sparse avg %d: %f != %f", i, aa.Avg[i], bb.Avg[i])
+		}
+		for ii := range vectors {
+			if a.Cov[i][ii] != b.Cov[i][ii] {
+				t.Fatalf("cov %d %d: %f != %f", i, ii, a.Cov[i][ii], b.Cov[i][ii])
+			}
+		}
+	}
+	for i, value := range aa.Sparse.Data {
+		if value != bb.Sparse.Data[i] {
+			t.Fatalf("sparse cov %d: %f != %f", i, value, bb.Sparse.Data[i])
+		}
+	}
+}
+
+func TestMorpheusConvergence(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	type T struct{}
+	vectors := normal[T](rng, 8, 16)
+	config := Config{
+		Iterations:    8,
+		Size:          16,
+		Divider:       1,
+		Accuracy:      8,
+		Convergence:   1e-2,
+		MaxIterations: 256,
+	}
+	adaptive := MorpheusGramSchmidt(1, config, vectors)
+	if adaptive.Iterations >= config.MaxIterations || !adaptive.Delta.Converged(config.Convergence) {
+		t.Fatalf("did not converge after %d iterations: %+v", adaptive.Iterations, adaptive.Delta)
+	}
+	config.Iterations, config.Convergence = adaptive.Iterations, 0
+	fixed := MorpheusGramSchmidt(1, config, vectors)
+	for i := range vectors {
+		for ii := range vectors {
+			if adaptive.Cov[i][ii] != fixed.Cov[i][ii] {
+				t.Fatalf("cov %d %d: %f != %f", i, ii, adaptive.Cov[i][ii], fixed.Cov[i][ii])
+			}
+		}
+	}
+
+	config.Iterations, config.Convergence, config.MaxIterations = 8, 1e-300, 20
+	ceiling := MorpheusGramSchmidt(1, config, vectors)
+	if ceiling.Iterations != config.MaxIterations || ceiling.Delta.Converged(config.Convergence) {
+		t.Fatalf("stopped after %d iterations: %+v", ceiling.Iterations, ceiling.Delta)
+	}
+}
+
+func TestAccumulator(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	const n, iterations = 6, 32
+	results := make([][]float64, iterations)
+	for i := range results {
+		for range n {
+			results[i] = append(results[i], 1e3+rng.Float64())
+		}
+	}
+	neighbors := make([][]int, n)
+	for i := range neighbors {
+		neighbors[i] = []int{i, (i + 1) % n}
+	}
+	dense, sparse := newAccumulator(n), newSparseAccumulator(neighbors)
+	for _, ranks := range results {
+		dense.add(ranks)
+		sparse.add(ranks)
+	}
+	r, s := dense.result(), sparse.result()
+
+	// the two pass statistics
+	avg := make([]float64, n)
+	for _, ranks := range results {
+		for i, value := range ranks {
+			avg[i] += value / iterations
+		}
+	}
+	cov := func(i, ii int) float64 {
+		sum := 0.0
+		for _, ranks := range results {
+			sum += (ranks[i] - avg[i]) * (ranks[ii] - avg[ii])
+		}
+		return sum / iterations
+	}
+	const tolerance = 1e-9
+	for i := range n {
+		if diff := math.Abs(r.Avg[i] - avg[i]); diff > tolerance {
+			t.Fatalf("avg %d is off by %g", i, diff)
+		}
+		if diff := math.Abs(r.Stddev[i] - math.Sqrt(cov(i, i))); diff > tolerance {
+			t.Fatalf("stddev %d is off by %g", i, diff)
+		}
+		for ii := range n {
+			if diff := math.Abs(r.Cov[i][ii] - cov(i, ii)); diff > tolerance {
+				t.Fatalf("cov %d %d is off by %g", i, ii, diff)
+			}
+			if r.Cov[i][ii] != r.Cov[ii][i] {
+				t.Fatalf("cov %d %d is not symmetric", i, ii)
+			}
+		}
+		indices, data := s.Sparse.Row(i)
+		for k, j := range indices {
+			if data[k] != r.Cov[i][j] {
+				t.Fatalf("sparse cov %d %d: %f != %f", i, j, data[k], r.Cov[i][j])
+			}
+		}
+	}
+}
+
+func TestIncremental(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	type T struct{}
+	vectors := normal[T](rng, 24, 16)
+	config := Config{
+		Iterations: 8,
+		Size:       16,
+		Divider:    2,
+	}
+	compare := func(name string, expected, result Result, tolerance float64) {
+		t.Helper()
+		for i := range vectors {
+			if diff := math.Abs(expected.Avg[i] - result.Avg[i]); diff > tolerance {
+				t.Fatalf("%s: avg %d drifted by %g", name, i, diff)
+			}
+			if diff := math.Abs(expected.Stddev[i] - result.Stddev[i]); diff > tolerance {
+				t.Fatalf("%s: stddev %d drifted by %g", name, i, diff)
+			}
+			for ii := range vectors {
+				if diff := math.Abs(expected.Cov[i][ii] - result.Cov[i][ii]); diff > tolerance {
+					t.Fatalf("%s: cov %d %d drifted by %g", name, i, ii, diff)
+				}
+			}
+		}
+	}
+	incremental := func(config Config, vectors []*Vector[T]) *Incremental[T] {
+		t.Helper()
+		incremental, err := NewIncremental(1, config, vectors)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return incremental
+	}
+	add := func(incremental *Incremental[T], vectors ...*Vector[T]) Result {
+		t.Helper()
+		result, err := incremental.Add(vectors...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+	// an incremental run starts out as Morpheus
+	compare("new", Morpheus(1, config, vectors), incremental(config, vectors).Result(), 0)
+	adaptive := config
+	adaptive.Convergence, adaptive.MaxIterations = 1e-3, 64
+	expected, result := Morpheus(1, adaptive, vectors), incremental(adaptive, vectors).Result()
+	if result.Iterations != expected.Iterations {
+		t.Fatalf("%d != %d iterations", result.Iterations, expected.Iterations)
+	}
+	compare("adaptive", expected, result, 0)
+
+	// adding vectors drifts from Morpheus on all of them by at most the tolerance of the ranker
+	for _, ranker := range []Ranker{GraphRanker{Tolerance: 1e-12}, HITSRanker{Tolerance: 1e-12}} {
+		config.Ranker = ranker
+		m := incremental(config, vectors[:16])
+		add(m, vectors[16:20]...)
+		result := add(m, vectors[20:]...)
+		compare(fmt.Sprintf("%T", ranker), Morpheus(1, config, vectors), result, 1e-9)
+	}
+
+	// the constraints are rebuilt for all of the vectors
+	graph := make(map[string]map[string]uint64)
+	for i, vector := range vectors {
+		vector.Word = fmt.Sprint(i % 4)
+		graph[vector.Word] = map[string]uint64{vector.Word: 1, fmt.Sprint((i + 1) % 4): 1}
+	}
+	words := func(vectors []*Vector[T]) []EdgeConstraint {
+		return []EdgeConstraint{NewWordConstraint(vectors, graph)}
+	}
+	config.Ranker = GraphRanker{Tolerance: 1e-12}
+	constrained := config
+	constrained.Constraints = words(vectors[:16])
+	m := incremental(constrained, vectors[:16])
+	if _, err := m.Add(vectors[16:]...); !errors.Is(err, ErrConstraint) {
+		t.Fatalf("expected ErrConstraint, got %v", err)
+	}
+	m.Constraints = words
+	result = add(m, vectors[16:]...)
+	constrained.Constraints = words(vectors)
+	compare("constraints", Morpheus(1, constrained, vectors), result, 1e-9)
+
+	// the accumulating ranker can't be grown and a teleport has to be extended before vectors are added
+	config.Ranker = &AccumulatingRanker{}
+	if _, err := NewIncremental(1, config, vectors); !errors.Is(err, ErrRanker) {
+		t.Fatalf("expected ErrRanker, got %v", err)
+	}
+	teleport := make([]float64, len(vectors))
+	for i := range teleport {
+		teleport[i] = float64(i%3 + 1)
+	}
+	config.Ranker = GraphRanker{Tolerance: 1e-12, Teleport: teleport[:16]}
+	m = incremental(config, vectors[:16])
+	if _, err := m.Add(vectors[16:]...); !errors.Is(err, ErrLength) {
+		t.Fatalf("expected ErrLength, got %v", err)
+	}
+	if len(m.Vectors) != 16 {
+		t.Fatalf("a rejected add kept %d vectors", len(m.Vectors))
+	}
+	config.Ranker = GraphRanker{Tolerance: 1e-12, Teleport: teleport}
+	m.Config.Ranker = config.Ranker
+	compare("teleport", Morpheus(1, config, vectors), add(m, vectors[16:]...), 1e-9)
+}
+
+// recorder records the first value of every projection it draws
+type recorder struct {
+	SoftmaxProjection
+	sync.Mutex
+	drawn []float32
+}
+
+// Project draws a pair of softmax matrices and records them
+func (r *recorder) Project(rng *rand.Rand, cols, rows int) (Matrix[float32], Matrix[float32]) {
+	a, b := r.SoftmaxProjection.Project(rng, cols, rows)
+	r.Lock()
+	defer r.Unlock()
+	r.drawn = append(r.drawn, a.Data[0], b.Data[0])
+	return a, b
+}
+
+func TestIncrementalSeeds(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	type T struct{}
+	vectors := normal[T](rng, 8, 16)
+	morpheus, incremental := &recorder{}, &recorder{}
+	config := Config{
+		Iterations: 8,
+		Size:       16,
+		Divider:    2,
+		Projection: morpheus,
+	}
+	Morpheus(1, config, vectors)
+	config.Projection = incremental
+	if _, err := NewIncremental(1, config, vectors); err != nil {
+		t.Fatal(err)
+	}
+	// the iterations of Morpheus run in any order
+	sort.Slice(morpheus.drawn, func(i, j int) bool { return morpheus.drawn[i] < morpheus.drawn[j] })
+	sort.Slice(incremental.drawn, func(i, j int) bool { return incremental.drawn[i] < incremental.drawn[j] })
+	if len(morpheus.drawn) != len(incremental.drawn) {
+		t.Fatalf("%d != %d projections", len(incremental.drawn), len(morpheus.drawn))
+	}
+	for i, value := range morpheus.drawn {
+		if incremental.drawn[i] != value {
+			t.Fatalf("projection %d: %f != %f", i, incremental.drawn[i], value)
+		}
+	}
+}
+
 func BenchmarkMorpheus(b *testing.B) {
 	rng := rand.New(rand.NewSource(1))
 	config := Config{
@@ -134,6 +761,52 @@ func BenchmarkPageRankFast(b *testing.B) {
 	}
 }
 
+func BenchmarkPageRankCSR(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	for b.Loop() {
+		adj := NewMatrix(1024, 1024, make([]float64, 1024*1024)...)
+		for i := range 1024 {
+			for j := range 1024 {
+				adj.Data[i*1024+j] = rng.Float64()
+			}
+		}
+		FromDense(adj).PageRank(.85, .001)
+	}
+}
+
+func BenchmarkPageRankCSR32(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	for b.Loop() {
+		adj := NewMatrix(1024, 1024, make([]float32, 1024*1024)...)
+		for i := range 1024 {
+			for j := range 1024 {
+				adj.Data[i*1024+j] = rng.Float32()
+			}
+		}
+		FromDense(adj).PageRank(.85, .001)
+	}
+}
+
+func benchmarkMulT(b *testing.B, cols, rows int) {
+	rng := rand.New(rand.NewSource(1))
+	m := NewMatrix(cols, rows, make([]float32, cols*rows)...)
+	for i := range m.Data {
+		m.Data[i] = rng.Float32()
+	}
+	buffer := make([]float32, rows*rows)
+	for b.Loop() {
+		m.MulT(m, buffer)
+	}
+}
+
+func BenchmarkMulT1024x1024(b *testing.B) {
+	benchmarkMulT(b, 1024, 1024)
+}
+
+func BenchmarkMulT8192x256(b *testing.B) {
+	benchmarkMulT(b, 256, 8192)
+}
+
 func BenchmarkRank(b *testing.B) {
 	rng := rand.New(rand.NewSource(1))
 	vectors := make([][]float32, 16)
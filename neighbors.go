@@ -0,0 +1,157 @@
+// Copyright 2025 The Morpheus Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math/rand"
+
+	"github.com/pointlander/morpheus/parallel"
+)
+
+// NeighborSearch finds the k nearest cosine neighbors of each row of a matrix with unit rows
+type NeighborSearch interface {
+	Search(rng *rand.Rand, x Matrix[float32], k int) [][]int
+}
+
+// neighbor is a neighbor of a row
+type neighbor struct {
+	Index  int
+	Cosine float32
+}
+
+// insert inserts n into a list of at most k neighbors sorted by decreasing cosine
+func insert(list []neighbor, k int, n neighbor) []neighbor {
+	if len(list) == k && list[k-1].Cosine >= n.Cosine {
+		return list
+	}
+	if len(list) < k {
+		list = append(list, n)
+	} else {
+		list[k-1] = n
+	}
+	for i := len(list) - 1; i > 0 && list[i-1].Cosine < list[i].Cosine; i-- {
+		list[i-1], list[i] = list[i], list[i-1]
+	}
+	return list
+}
+
+// neighborIndexes returns the indexes of the neighbor lists
+func neighborIndexes(lists [][]neighbor) [][]int {
+	result := make([][]int, len(lists))
+	for i, list := range lists {
+		result[i] = make([]int, len(list))
+		for ii, n := range list {
+			result[i][ii] = n.Index
+		}
+	}
+	return result
+}
+
+// forBlocks processes the blocks of n rows in parallel
+func forBlocks(n, block int, process func(start, end int)) {
+	parallel.For((n+block-1)/block, func(index int) {
+		start := index * block
+		process(start, min(start+block, n))
+	})
+}
+
+// BlockedSearch is an exact search that compares blocks of rows with each other
+type BlockedSearch struct {
+	// Block is the number of rows in a block, defaults to 1024
+	Block int
+}
+
+// Search finds the k nearest neighbors of each row
+func (b BlockedSearch) Search(rng *rand.Rand, x Matrix[float32], k int) [][]int {
+	block := b.Block
+	if block <= 0 {
+		block = 1024
+	}
+	lists := make([][]neighbor, x.Rows)
+	forBlocks(x.Rows, block, func(start, end int) {
+		rows := NewMatrix(x.Cols, end-start, x.Data[start*x.Cols:end*x.Cols]...)
+		for j := 0; j < x.Rows; j += block {
+			stop := min(j+block, x.Rows)
+			cols := NewMatrix(x.Cols, stop-j, x.Data[j*x.Cols:stop*x.Cols]...)
+			cs := cols.MulT(rows)
+			for r := range cs.Rows {
+				for c := range cs.Cols {
+					if j+c == start+r {
+						continue
+					}
+					lists[start+r] = insert(lists[start+r], k, neighbor{
+						Index:  j + c,
+						Cosine: cs.Data[r*cs.Cols+c],
+					})
+				}
+			}
+		}
+	})
+	return neighborIndexes(lists)
+}
+
+// LSHSearch is an approximate search that only compares rows which share a random hyperplane hash
+type LSHSearch struct {
+	// Bits is the number of hyperplanes per table, defaults to 16
+	Bits int
+	// Tables is the number of hash tables, defaults to 8
+	Tables int
+}
+
+// Search finds the approximate k nearest neighbors of each row
+func (l LSHSearch) Search(rng *rand.Rand, x Matrix[float32], k int) [][]int {
+	bits, tables := l.Bits, l.Tables
+	if bits <= 0 {
+		bits = 16
+	}
+	if bits > 64 {
+		bits = 64
+	}
+	if tables <= 0 {
+		tables = 8
+	}
+	keys := make([][]uint64, tables)
+	buckets := make([]map[uint64][]int, tables)
+	for t := range tables {
+		planes := NewMatrix(x.Cols, bits, make([]float32, x.Cols*bits)...)
+		for i := range planes.Data {
+			planes.Data[i] = float32(rng.NormFloat64())
+		}
+		projected := planes.MulT(x)
+		keys[t] = make([]uint64, x.Rows)
+		buckets[t] = make(map[uint64][]int)
+		for i := range x.Rows {
+			key := uint64(0)
+			for ii, value := range projected.Data[i*bits : (i+1)*bits] {
+				if value > 0 {
+					key |= 1 << ii
+				}
+			}
+			keys[t][i] = key
+			buckets[t][key] = append(buckets[t][key], i)
+		}
+	}
+
+	lists := make([][]neighbor, x.Rows)
+	forBlocks(x.Rows, 1024, func(start, end int) {
+		seen := make([]int, x.Rows)
+		for i := start; i < end; i++ {
+			row := x.Data[i*x.Cols : (i+1)*x.Cols]
+			for t := range tables {
+				for _, j := range buckets[t][keys[t][i]] {
+					if j == i || seen[j] == i+1 {
+						continue
+					}
+					seen[j] = i + 1
+					lists[i] = insert(lists[i], k, neighbor{
+						Index:  j,
+						Cosine: dot(row, x.Data[j*x.Cols:(j+1)*x.Cols]),
+					})
+				}
+			}
+		}
+	})
+	return neighborIndexes(lists)
+}
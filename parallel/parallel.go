@@ -0,0 +1,35 @@
+// Copyright 2025 The Morpheus Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package parallel runs the iterations of a loop on a pool of goroutines
+package parallel
+
+import "runtime"
+
+// For calls process with each index from 0 to n on at most runtime.NumCPU goroutines at a time
+// and returns when every call has returned
+func For(n int, process func(index int)) {
+	done := make(chan bool, 8)
+	run := func(index int) {
+		process(index)
+		done <- true
+	}
+	index, flights, cpus := 0, 0, runtime.NumCPU()
+	for index < n && flights < cpus {
+		go run(index)
+		index++
+		flights++
+	}
+	for index < n {
+		<-done
+		flights--
+
+		go run(index)
+		index++
+		flights++
+	}
+	for range flights {
+		<-done
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright 2025 The Morpheus Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package parallel
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFor(t *testing.T) {
+	for _, n := range []int{0, 1, runtime.NumCPU(), 3*runtime.NumCPU() + 1} {
+		calls := make([]int64, n)
+		var running, most int64
+		For(n, func(index int) {
+			current := atomic.AddInt64(&running, 1)
+			for {
+				previous := atomic.LoadInt64(&most)
+				if current <= previous || atomic.CompareAndSwapInt64(&most, previous, current) {
+					break
+				}
+			}
+			atomic.AddInt64(&calls[index], 1)
+			atomic.AddInt64(&running, -1)
+		})
+		for index, value := range calls {
+			if value != 1 {
+				t.Fatalf("%d: index %d was processed %d times", n, index, value)
+			}
+		}
+		if most > int64(runtime.NumCPU()) {
+			t.Fatalf("%d: %d goroutines ran at once", n, most)
+		}
+	}
+}
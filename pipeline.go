@@ -0,0 +1,541 @@
+// Copyright 2025 The Morpheus Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+
+	"github.com/pointlander/morpheus/parallel"
+)
+
+// Projection projects the input vectors through random matrices
+type Projection interface {
+	// Width is the width of the projection input for vectors of length size
+	Width(size int) int
+	// Encode encodes a vector into a row of the projection input
+	Encode(size int, row, vector []float32)
+	// Project draws a pair of projection matrices
+	Project(rng *rand.Rand, cols, rows int) (Matrix[float32], Matrix[float32])
+}
+
+// Similarity computes the similarity graph of two projections
+type Similarity interface {
+	Similarity(x, y Matrix[float32]) Matrix[float32]
+}
+
+// EdgeFilter decides if an edge of the similarity graph is linked and with what weight
+type EdgeFilter interface {
+	Filter(from, to int, weight float32) (float64, bool)
+}
+
+// Ranker ranks the nodes of the similarity graph
+type Ranker interface {
+	Rank(rng *rand.Rand, cs Matrix[float32], filter EdgeFilter) []float64
+}
+
+// Sequential is implemented by parts whose state carries over from one iteration to the next,
+// the iterations of a run with a sequential part are run in order on one goroutine
+type Sequential interface {
+	Sequential()
+}
+
+// sequential returns true if any of the parts is sequential
+func sequential(parts ...any) bool {
+	for _, part := range parts {
+		if _, ok := part.(Sequential); ok {
+			return true
+		}
+		if c, ok := part.(constrained); ok {
+			for _, constraint := range c.constraints {
+				if sequential(constraint) {
+					return true
+				}
+			}
+			if sequential(c.filter) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// iterate runs process for each iteration on a pool of goroutines, or in order if sequential is true
+func iterate(iterations int, sequential bool, process func(iteration int)) {
+	if sequential {
+		for iteration := range iterations {
+			process(iteration)
+		}
+		return
+	}
+	parallel.For(iterations, process)
+}
+
+// gaussian draws a pair of gaussian matrices
+func gaussian(rng *rand.Rand, cols, rows int) (Matrix[float32], Matrix[float32]) {
+	a, b := NewMatrix(cols, rows, make([]float32, cols*rows)...),
+		NewMatrix(cols, rows, make([]float32, cols*rows)...)
+	index := 0
+	for range a.Rows {
+		for range a.Cols {
+			a.Data[index] = float32(rng.NormFloat64())
+			b.Data[index] = float32(rng.NormFloat64())
+			index++
+		}
+	}
+	return a, b
+}
+
+// SoftmaxProjection splits vectors into positive and negative halves and projects them with softmax matrices
+type SoftmaxProjection struct{}
+
+// Width is the width of the projection input
+func (SoftmaxProjection) Width(size int) int {
+	return 2 * size
+}
+
+// Encode puts positive values in the first half and negated negative values in the second half
+func (SoftmaxProjection) Encode(size int, row, vector []float32) {
+	for i, value := range vector {
+		if value < 0 {
+			row[size+i] = -value
+			continue
+		}
+		row[i] = value
+	}
+}
+
+// Project draws a pair of softmax matrices
+func (SoftmaxProjection) Project(rng *rand.Rand, cols, rows int) (Matrix[float32], Matrix[float32]) {
+	a, b := gaussian(rng, cols, rows)
+	return a.Softmax(1), b.Softmax(1)
+}
+
+// GramSchmidtProjection projects vectors with orthonormal matrices
+type GramSchmidtProjection struct{}
+
+// Width is the width of the projection input
+func (GramSchmidtProjection) Width(size int) int {
+	return size
+}
+
+// Encode copies the vector into the row
+func (GramSchmidtProjection) Encode(size int, row, vector []float32) {
+	copy(row, vector)
+}
+
+// Project draws a pair of orthonormal matrices
+func (GramSchmidtProjection) Project(rng *rand.Rand, cols, rows int) (Matrix[float32], Matrix[float32]) {
+	a, b := gaussian(rng, cols, rows)
+	return a.GramSchmidt().T(), b.GramSchmidt().T()
+}
+
+// FixedProjection draws the projection matrices once from its own seed and reuses them for every iteration
+type FixedProjection struct {
+	Projection
+	Seed int64
+	once sync.Once
+	a, b Matrix[float32]
+}
+
+// Project draws the projection matrices on the first call and returns them after that
+func (f *FixedProjection) Project(rng *rand.Rand, cols, rows int) (Matrix[float32], Matrix[float32]) {
+	f.once.Do(func() {
+		f.a, f.b = f.Projection.Project(rand.New(rand.NewSource(f.Seed)), cols, rows)
+	})
+	return f.a, f.b
+}
+
+// Kernel is a similarity that can also be computed one pair of rows at a time, as in sparse mode
+type Kernel interface {
+	Similarity
+	// Normalize prepares the rows of a projection for Pair
+	Normalize(x Matrix[float32]) Matrix[float32]
+	// Pair is the similarity of two normalized rows
+	Pair(x, y []float32) float32
+}
+
+// kernel applies f to the dot products of the normalized rows of y with the normalized rows of x
+func kernel(k Kernel, x, y Matrix[float32], f func(ab float32) float32) Matrix[float32] {
+	cs := k.Normalize(y).MulT(k.Normalize(x))
+	for i, value := range cs.Data {
+		cs.Data[i] = f(value)
+	}
+	return cs
+}
+
+// Cosine is cosine similarity
+type Cosine struct{}
+
+// Similarity computes the cosine similarity of each row of y with each row of x
+func (Cosine) Similarity(x, y Matrix[float32]) Matrix[float32] {
+	xx := x.Unit()
+	yy := y.Unit()
+	return yy.MulT(xx)
+}
+
+// Normalize scales the rows to unit length
+func (Cosine) Normalize(x Matrix[float32]) Matrix[float32] {
+	return x.Unit()
+}
+
+// Pair is the dot product of two unit rows
+func (Cosine) Pair(x, y []float32) float32 {
+	return dot(x, y)
+}
+
+// Dot is the raw dot product
+type Dot struct{}
+
+// Similarity computes the dot product of each row of y with each row of x
+func (Dot) Similarity(x, y Matrix[float32]) Matrix[float32] {
+	return y.MulT(x)
+}
+
+// Normalize returns the rows as they are
+func (Dot) Normalize(x Matrix[float32]) Matrix[float32] {
+	return x
+}
+
+// Pair is the dot product of two rows
+func (Dot) Pair(x, y []float32) float32 {
+	return dot(x, y)
+}
+
+// RBF is the gaussian radial basis function exp(-|x-y|^2 / (2 Bandwidth^2))
+type RBF struct {
+	// Bandwidth defaults to 1
+	Bandwidth float64
+}
+
+// scale is -1 / (2 Bandwidth^2)
+func (r RBF) scale() float64 {
+	bandwidth := r.Bandwidth
+	if bandwidth <= 0 {
+		bandwidth = 1
+	}
+	return -1 / (2 * bandwidth * bandwidth)
+}
+
+// Similarity computes the rbf of each row of y with each row of x
+func (r RBF) Similarity(x, y Matrix[float32]) Matrix[float32] {
+	scale := r.scale()
+	cs := y.MulT(x)
+	for i := range cs.Rows {
+		xx := x.Data[i*x.Cols : (i+1)*x.Cols]
+		for ii := range cs.Cols {
+			yy := y.Data[ii*y.Cols : (ii+1)*y.Cols]
+			distance := dot(xx, xx) + dot(yy, yy) - 2*cs.Data[i*cs.Cols+ii]
+			cs.Data[i*cs.Cols+ii] = float32(math.Exp(scale * float64(max(distance, 0))))
+		}
+	}
+	return cs
+}
+
+// Normalize returns the rows as they are
+func (RBF) Normalize(x Matrix[float32]) Matrix[float32] {
+	return x
+}
+
+// Pair is the rbf of two rows
+func (r RBF) Pair(x, y []float32) float32 {
+	distance := float32(0)
+	for i, value := range x {
+		diff := value - y[i]
+		distance += diff * diff
+	}
+	return float32(math.Exp(r.scale() * float64(distance)))
+}
+
+// Angular is one minus the angle between the rows divided by pi
+type Angular struct{}
+
+// angular converts a cosine to the angular similarity
+func angular(cosine float32) float32 {
+	return float32(1 - math.Acos(math.Max(-1, math.Min(1, float64(cosine))))/math.Pi)
+}
+
+// Similarity computes the angular similarity of each row of y with each row of x
+func (a Angular) Similarity(x, y Matrix[float32]) Matrix[float32] {
+	return kernel(a, x, y, angular)
+}
+
+// Normalize scales the rows to unit length
+func (Angular) Normalize(x Matrix[float32]) Matrix[float32] {
+	return x.Unit()
+}
+
+// Pair is the angular similarity of two unit rows
+func (Angular) Pair(x, y []float32) float32 {
+	return angular(dot(x, y))
+}
+
+// RectifiedCosine is cosine similarity with the negative similarities set to zero
+type RectifiedCosine struct{}
+
+// rectify sets negative values to zero
+func rectify(value float32) float32 {
+	return max(value, 0)
+}
+
+// Similarity computes the rectified cosine similarity of each row of y with each row of x
+func (r RectifiedCosine) Similarity(x, y Matrix[float32]) Matrix[float32] {
+	return kernel(r, x, y, rectify)
+}
+
+// Normalize scales the rows to unit length
+func (RectifiedCosine) Normalize(x Matrix[float32]) Matrix[float32] {
+	return x.Unit()
+}
+
+// Pair is the rectified dot product of two unit rows
+func (RectifiedCosine) Pair(x, y []float32) float32 {
+	return rectify(dot(x, y))
+}
+
+// Kernels are the kernels by name
+var Kernels = map[string]Kernel{
+	"cosine":    Cosine{},
+	"dot":       Dot{},
+	"rbf":       RBF{},
+	"angular":   Angular{},
+	"rectified": RectifiedCosine{},
+}
+
+// AbsFilter links every edge with the absolute value of its weight
+type AbsFilter struct{}
+
+// Filter returns the absolute value of the weight
+func (AbsFilter) Filter(from, to int, weight float32) (float64, bool) {
+	if weight < 0 {
+		weight = -weight
+	}
+	return float64(weight), true
+}
+
+// SignedFilter links every edge with its weight
+type SignedFilter struct{}
+
+// Filter returns the weight
+func (SignedFilter) Filter(from, to int, weight float32) (float64, bool) {
+	return float64(weight), true
+}
+
+// NoiseFilter adds uniform noise to every edge, the noise continues from one iteration to the next
+type NoiseFilter struct {
+	RNG   RNG
+	Scale float64
+}
+
+// Sequential marks the filter as sequential
+func (n *NoiseFilter) Sequential() {}
+
+// Filter returns the weight plus noise
+func (n *NoiseFilter) Filter(from, to int, weight float32) (float64, bool) {
+	return float64(weight) + n.Scale*float64(n.RNG.Float32()), true
+}
+
+// link links the filtered edges of the similarity graph into a sparse matrix
+func link(cs Matrix[float32], filter EdgeFilter) CSR[float64] {
+	graph := CSR[float64]{
+		Size: Size{
+			Cols: cs.Cols,
+			Rows: cs.Rows,
+		},
+		Indptr: make([]int, cs.Rows+1),
+	}
+	for i := range cs.Rows {
+		for ii := range cs.Cols {
+			if weight, ok := filter.Filter(i, ii, cs.Data[i*cs.Cols+ii]); ok {
+				graph.Indices = append(graph.Indices, ii)
+				graph.Data = append(graph.Data, weight)
+			}
+		}
+		graph.Indptr[i+1] = len(graph.Indices)
+	}
+	return graph
+}
+
+// GraphRanker ranks with the power iteration pagerank, negative edges move rank by their magnitude
+// unless Signed is set
+type GraphRanker struct {
+	// Tolerance is the l1 tolerance of the pagerank, defaults to 1e-3
+	Tolerance float64
+	// Teleport is the optional teleport distribution of a personalized pagerank with a value for each vector
+	Teleport []float64
+	// Signed ranks with the signed pagerank so negative edges give negative ranks
+	Signed bool
+}
+
+// tolerance returns the tolerance with its default filled in
+func (g GraphRanker) tolerance() float64 {
+	if g.Tolerance > 0 {
+		return g.Tolerance
+	}
+	return 1e-3
+}
+
+// Rank links the filtered edges into a new graph and ranks it
+func (g GraphRanker) Rank(rng *rand.Rand, cs Matrix[float32], filter EdgeFilter) []float64 {
+	return g.rank(link(cs, filter))
+}
+
+// rank ranks the graph with the signed or the personalized pagerank and panics on an error
+func (g GraphRanker) rank(graph CSR[float64], warm ...[]float64) []float64 {
+	ranks, err := g.pagerank(graph, warm...)
+	if err != nil {
+		panic(err)
+	}
+	return ranks
+}
+
+// pagerank ranks the graph with the signed or the personalized pagerank
+func (g GraphRanker) pagerank(graph CSR[float64], warm ...[]float64) ([]float64, error) {
+	if g.Signed {
+		return graph.SignedPageRank(1.0, g.tolerance(), g.Teleport)
+	}
+	return graph.PersonalizedPageRank(1.0, g.tolerance(), g.Teleport, warm...)
+}
+
+// AccumulatingRanker adds the edges of every iteration to the same graph and ranks the accumulated graph,
+// each pagerank is warm started from the previous ranks
+type AccumulatingRanker struct {
+	GraphRanker
+	graph Matrix[float64]
+	ranks []float64
+}
+
+// Sequential marks the ranker as sequential
+func (a *AccumulatingRanker) Sequential() {}
+
+// Rank adds the filtered edges to the graph and ranks it
+func (a *AccumulatingRanker) Rank(rng *rand.Rand, cs Matrix[float32], filter EdgeFilter) []float64 {
+	if a.graph.Data == nil {
+		a.graph = NewMatrix(cs.Cols, cs.Rows, make([]float64, cs.Cols*cs.Rows)...)
+	}
+	graph := link(cs, filter)
+	for i := range graph.Rows {
+		indices, data := graph.Row(i)
+		for ii, j := range indices {
+			a.graph.Data[i*a.graph.Cols+j] += data[ii]
+		}
+	}
+	var warm [][]float64
+	if a.ranks != nil {
+		warm = append(warm, a.ranks)
+	}
+	a.ranks = a.rank(FromDense(a.graph), warm...)
+	return a.ranks
+}
+
+// CountingRanker ranks with the counting based pagerank
+type CountingRanker struct {
+	Accuracy int
+	// Teleport is the optional teleport distribution of a personalized pagerank with a value for each vector
+	Teleport []float64
+}
+
+// Rank filters the edges in place and ranks the graph
+func (c CountingRanker) Rank(rng *rand.Rand, cs Matrix[float32], filter EdgeFilter) []float64 {
+	for i := range cs.Rows {
+		for ii := range cs.Cols {
+			index := i*cs.Cols + ii
+			weight, ok := filter.Filter(i, ii, cs.Data[index])
+			if !ok {
+				weight = 0
+			}
+			cs.Data[index] = float32(weight)
+		}
+	}
+	accuracy := 256
+	if c.Accuracy > 0 {
+		accuracy = c.Accuracy
+	}
+	var teleport [][]float32
+	if c.Teleport != nil {
+		teleport = append(teleport, make([]float32, len(c.Teleport)))
+		for i, value := range c.Teleport {
+			teleport[0][i] = float32(value)
+		}
+	}
+	result := PageRank(1.0, accuracy, rng.Uint32(), cs, teleport...)
+	r := make([]float64, len(result.Data))
+	for key, value := range result.Data {
+		r[key] = float64(value)
+	}
+	return r
+}
+
+// filtered converts the filtered edges of the similarity graph to a dense matrix
+func filtered(cs Matrix[float32], filter EdgeFilter) Matrix[float64] {
+	graph := NewMatrix(cs.Cols, cs.Rows, make([]float64, cs.Cols*cs.Rows)...)
+	for i := range cs.Rows {
+		for ii := range cs.Cols {
+			if weight, ok := filter.Filter(i, ii, cs.Data[i*cs.Cols+ii]); ok {
+				graph.Data[i*cs.Cols+ii] = weight
+			}
+		}
+	}
+	return graph
+}
+
+// HITSRanker ranks with the hits authority scores or the hub scores
+type HITSRanker struct {
+	// Tolerance is the l1 tolerance of the scores, defaults to 1e-6
+	Tolerance float64
+	// Hubs ranks with the hub scores instead of the authority scores
+	Hubs bool
+}
+
+// Rank computes the hits scores of the filtered graph
+func (h HITSRanker) Rank(rng *rand.Rand, cs Matrix[float32], filter EdgeFilter) []float64 {
+	hubs, authorities := filtered(cs, filter).HITS(centralityTolerance(h.Tolerance))
+	if h.Hubs {
+		return hubs
+	}
+	return authorities
+}
+
+// EigenvectorRanker ranks with the eigenvector centrality
+type EigenvectorRanker struct {
+	// Tolerance is the l1 tolerance of the centrality, defaults to 1e-6
+	Tolerance float64
+}
+
+// Rank computes the eigenvector centrality of the filtered graph
+func (e EigenvectorRanker) Rank(rng *rand.Rand, cs Matrix[float32], filter EdgeFilter) []float64 {
+	return filtered(cs, filter).EigenvectorCentrality(centralityTolerance(e.Tolerance))
+}
+
+// KatzRanker ranks with the katz centrality
+type KatzRanker struct {
+	// Alpha is the attenuation factor, defaults to a value that converges
+	Alpha float64
+	// Tolerance is the l1 tolerance of the centrality, defaults to 1e-6
+	Tolerance float64
+}
+
+// Rank computes the katz centrality of the filtered graph
+func (k KatzRanker) Rank(rng *rand.Rand, cs Matrix[float32], filter EdgeFilter) []float64 {
+	return filtered(cs, filter).Katz(k.Alpha, centralityTolerance(k.Tolerance))
+}
+
+// centralityTolerance returns the tolerance of a centrality ranker with its default filled in
+func centralityTolerance(t float64) float64 {
+	if t > 0 {
+		return t
+	}
+	return 1e-6
+}
+
+// Rankers are the rankers by name, a nil ranker is the default pagerank of the mode
+var Rankers = map[string]Ranker{
+	"pagerank":    nil,
+	"hits":        HITSRanker{},
+	"hubs":        HITSRanker{Hubs: true},
+	"eigenvector": EigenvectorRanker{},
+	"katz":        KatzRanker{},
+}
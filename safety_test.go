@@ -0,0 +1,145 @@
+// Copyright 2025 The Morpheus Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// poisoned draws a matrix with zero rows, NaN and infinite values
+func poisoned[T Float](rng *rand.Rand, cols, rows int) Matrix[T] {
+	m := NewMatrix(cols, rows, make([]T, cols*rows)...)
+	for i := range m.Data {
+		m.Data[i] = T(rng.Float64())
+		if rng.Intn(2) == 0 {
+			m.Data[i] = -m.Data[i]
+		}
+	}
+	specials := []float64{0, math.NaN(), math.Inf(1), math.Inf(-1)}
+	for range rng.Intn(cols * rows) {
+		m.Data[rng.Intn(len(m.Data))] = T(specials[rng.Intn(len(specials))])
+	}
+	for range rng.Intn(rows) {
+		row := rng.Intn(rows)
+		for i := range cols {
+			m.Data[row*cols+i] = 0
+		}
+	}
+	return m
+}
+
+// check fails the test if a value isn't finite or if the values should sum to one and don't
+func check[T Float](t *testing.T, name string, values []T, stochastic bool) {
+	t.Helper()
+	sum := 0.0
+	for i, value := range values {
+		if math.IsNaN(float64(value)) || math.IsInf(float64(value), 0) {
+			t.Fatalf("%s: %d is %f", name, i, value)
+		}
+		sum += math.Abs(float64(value))
+	}
+	if stochastic && math.Abs(sum-1) > 1e-4 {
+		t.Fatalf("%s: sums to %f", name, sum)
+	}
+}
+
+// checkRows checks each row of a matrix
+func checkRows[T Float](t *testing.T, name string, m Matrix[T], stochastic bool) {
+	t.Helper()
+	for i := range m.Rows {
+		check(t, name, m.Data[i*m.Cols:(i+1)*m.Cols], stochastic)
+	}
+}
+
+// checkUnit checks that the rows of a matrix have unit or zero length
+func checkUnit[T Float](t *testing.T, m Matrix[T]) {
+	t.Helper()
+	checkRows(t, "unit", m, false)
+	for i := range m.Rows {
+		row := m.Data[i*m.Cols : (i+1)*m.Cols]
+		norm := math.Sqrt(float64(dot(row, row)))
+		if norm != 0 && math.Abs(norm-1) > 1e-4 {
+			t.Fatalf("unit: row %d has norm %f", i, norm)
+		}
+	}
+}
+
+func TestNonFinite(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for range 64 {
+		size := 8 + rng.Intn(8)
+		m := poisoned[float64](rng, size, size)
+		n := poisoned[float64](rng, size, size)
+		m32 := poisoned[float32](rng, size, size)
+
+		checkRows(t, "softmax", m.Softmax(1), true)
+		checkRows(t, "softmax32", m32.Softmax(1), true)
+		row := append([]float64{}, m.Data[:size]...)
+		softmax(row)
+		check(t, "softmax row", row, true)
+		checkUnit(t, m.Unit())
+		checkUnit(t, m32.Unit())
+		check(t, "cs", []float64{m.CS(n), NCS(m.Data[:size], n.Data[:size])}, false)
+
+		copied := NewMatrix(size, size, append([]float64{}, m.Data...)...)
+		check(t, "pagerank", PageRank(.85, 8, 1, copied, n.Data[:size]).Data, true)
+		copied = NewMatrix(size, size, append([]float64{}, m.Data...)...)
+		checkRows(t, "markov", PageRankMarkov(.85, 8, 1, copied), true)
+		copied = NewMatrix(size, size, append([]float64{}, m.Data...)...)
+		markov := LearnMarkov(.85, 8, 1, copied)
+		check(t, "flow", markov.Flow().Data, true)
+		for state := range markov.Counts {
+			check(t, "transition", markov.Transition(state/size, state%size), true)
+		}
+		for _, node := range markov.Walk(rng, 0, 0, 32) {
+			if node < 0 || node >= size {
+				t.Fatalf("walk: %d is not a node", node)
+			}
+		}
+
+		graph := FromDense(m)
+		check(t, "csr pagerank", must(graph.PageRank(.85, 1e-6)), true)
+		check(t, "personalized pagerank", must(graph.PersonalizedPageRank(.85, 1e-6, n.Data[:size])), true)
+		check(t, "signed pagerank", must(graph.SignedPageRank(.85, 1e-6, nil)), true)
+
+		hubs, authorities := m.HITS(1e-6)
+		check(t, "hubs", hubs, true)
+		check(t, "authorities", authorities, true)
+		check(t, "eigenvector", m.EigenvectorCentrality(1e-6), true)
+		check(t, "katz", m.Katz(0, 1e-6), true)
+
+		var model Model
+		var state [order]Markov
+		counts := make([]float32, size)
+		for i, value := range m32.Data[:size] {
+			counts[i] = abs(value)
+		}
+		model[0] = map[Markov][]float32{state[0]: counts}
+		check(t, "lookup", Lookup(&state, &model), true)
+	}
+}
+
+func TestZeroVector(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	type T struct{}
+	vectors := normal[T](rng, 8, 8)
+	vectors[0].Vector = make([]float32, 8)
+	config := Config{
+		Iterations: 4,
+		Size:       8,
+		Divider:    1,
+		Accuracy:   8,
+	}
+	for _, result := range []Result{
+		Morpheus(1, config, vectors),
+		MorpheusGramSchmidt(1, config, vectors),
+	} {
+		for i := range result.Cov {
+			check(t, "cov", result.Cov[i], false)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+// Copyright 2025 The Morpheus Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import "fmt"
+
+const (
+	// MaxPowerIterations is the maximum number of power iterations
+	MaxPowerIterations = 1024
+)
+
+// CSR is a compressed sparse row matrix
+type CSR[T Float] struct {
+	Size
+	// Indptr is the offset of each row in Indices and Data
+	Indptr []int
+	// Indices are the columns of the entries
+	Indices []int
+	// Data are the values of the entries
+	Data []T
+}
+
+// NewCSR creates a sparse matrix with the pattern of the neighbor lists
+func NewCSR[T Float](cols int, neighbors [][]int) CSR[T] {
+	c := CSR[T]{
+		Size: Size{
+			Cols: cols,
+			Rows: len(neighbors),
+		},
+		Indptr: make([]int, len(neighbors)+1),
+	}
+	for i, row := range neighbors {
+		c.Indptr[i+1] = c.Indptr[i] + len(row)
+	}
+	c.Indices = make([]int, 0, c.Indptr[len(neighbors)])
+	for _, row := range neighbors {
+		c.Indices = append(c.Indices, row...)
+	}
+	c.Data = make([]T, len(c.Indices))
+	return c
+}
+
+// Row returns the columns and values of row i
+func (c CSR[T]) Row(i int) ([]int, []T) {
+	start, end := c.Indptr[i], c.Indptr[i+1]
+	return c.Indices[start:end], c.Data[start:end]
+}
+
+// At returns the value at row i and column j
+func (c CSR[T]) At(i, j int) T {
+	value, _ := c.Lookup(i, j)
+	return value
+}
+
+// Lookup returns the value at row i and column j and whether the entry is in the matrix
+func (c CSR[T]) Lookup(i, j int) (T, bool) {
+	indices, data := c.Row(i)
+	for k, index := range indices {
+		if index == j {
+			return data[k], true
+		}
+	}
+	return 0, false
+}
+
+// Dense converts the sparse matrix to a dense matrix
+func (c CSR[T]) Dense() Matrix[T] {
+	m := NewMatrix(c.Cols, c.Rows, make([]T, c.Cols*c.Rows)...)
+	for i := range c.Rows {
+		indices, data := c.Row(i)
+		for k, index := range indices {
+			m.Data[i*m.Cols+index] += data[k]
+		}
+	}
+	return m
+}
+
+// Symmetric returns the square matrix with the union of its pattern and the pattern of its transpose,
+// an entry that is only in one of them takes the value of its transposed entry
+func (c CSR[T]) Symmetric() CSR[T] {
+	neighbors, values := make([][]int, c.Rows), make([][]T, c.Rows)
+	for i := range c.Rows {
+		indices, data := c.Row(i)
+		neighbors[i] = append(neighbors[i], indices...)
+		values[i] = append(values[i], data...)
+	}
+	for i := range c.Rows {
+		indices, data := c.Row(i)
+		for k, j := range indices {
+			if _, ok := c.Lookup(j, i); !ok {
+				neighbors[j] = append(neighbors[j], i)
+				values[j] = append(values[j], data[k])
+			}
+		}
+	}
+	s := NewCSR[T](c.Cols, neighbors)
+	for i := range s.Rows {
+		_, data := s.Row(i)
+		copy(data, values[i])
+	}
+	return s
+}
+
+// FromDense converts the non zero entries of a matrix to a sparse matrix
+func FromDense[T Float](m Matrix[T]) CSR[T] {
+	c := CSR[T]{
+		Size: Size{
+			Cols: m.Cols,
+			Rows: m.Rows,
+		},
+		Indptr: make([]int, m.Rows+1),
+	}
+	for i := range m.Rows {
+		for ii, value := range m.Data[i*m.Cols : (i+1)*m.Cols] {
+			if value != 0 {
+				c.Indices = append(c.Indices, ii)
+				c.Data = append(c.Data, value)
+			}
+		}
+		c.Indptr[i+1] = len(c.Indices)
+	}
+	return c
+}
+
+// abs is the absolute value
+func abs[T Float | int64](x T) T {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// distribution normalizes the teleport distribution of n nodes, a nil or zero teleport is uniform
+func distribution[T Float](n int, teleport []T) ([]T, error) {
+	p := make([]T, n)
+	if teleport == nil {
+		for i := range p {
+			p[i] = 1 / T(n)
+		}
+		return p, nil
+	}
+	if len(teleport) != n {
+		return nil, fmt.Errorf("%w: teleport has %d values for %d nodes", ErrLength, len(teleport), n)
+	}
+	var sum T
+	for i, value := range teleport {
+		p[i] = abs(finite(value))
+		sum += p[i]
+	}
+	stochastic(p, sum)
+	return p, nil
+}
+
+// PageRank is a power iteration pagerank with damping a that stops when the l1 change is at most e,
+// rows are normalized by their absolute sum and the rank of rows without weight is spread uniformly,
+// the iteration starts from the optional warm rank instead of the uniform rank. A negative edge moves
+// rank as a positive edge of the same magnitude does, the alixaxel pagerank this replaced normalized
+// rows by their signed sum instead, see SignedPageRank for ranks that keep the sign of the edges
+func (c CSR[T]) PageRank(a, e T, warm ...[]T) ([]T, error) {
+	return c.PersonalizedPageRank(a, e, nil, warm...)
+}
+
+// PersonalizedPageRank is PageRank that teleports and spreads the rank of rows without weight
+// with the teleport distribution, a nil teleport is uniform. The teleport distribution and the
+// warm rank must have a value for each row
+func (c CSR[T]) PersonalizedPageRank(a, e T, teleport []T, warm ...[]T) ([]T, error) {
+	n := c.Rows
+	p, err := distribution(n, teleport)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]T, n)
+	for i := range n {
+		_, data := c.Row(i)
+		for _, value := range data {
+			out[i] += abs(finite(value))
+		}
+	}
+	rank, next := make([]T, n), make([]T, n)
+	if len(warm) == 1 {
+		if len(warm[0]) != n {
+			return nil, fmt.Errorf("%w: warm rank has %d values for %d nodes", ErrLength, len(warm[0]), n)
+		}
+		for i, value := range warm[0] {
+			rank[i] = finite(value)
+		}
+	} else {
+		for i := range rank {
+			rank[i] = 1 / T(n)
+		}
+	}
+	for range MaxPowerIterations {
+		var leak T
+		for i := range next {
+			next[i] = 0
+		}
+		for i := range n {
+			if out[i] == 0 {
+				leak += rank[i]
+				continue
+			}
+			indices, data := c.Row(i)
+			scale := a * rank[i] / out[i]
+			for k, index := range indices {
+				next[index] += scale * abs(finite(data[k]))
+			}
+		}
+		jump := (1 - a) + a*leak
+		var delta T
+		for i := range next {
+			next[i] += jump * p[i]
+			delta += abs(next[i] - rank[i])
+		}
+		rank, next = next, rank
+		if delta <= e {
+			break
+		}
+	}
+	return rank, nil
+}
+
+// SignedPageRank ranks a graph with negative edges, a walk moves with the absolute weights as in PageRank
+// and each step counts the sign of the edge it took, a teleport from a row without weight counts as positive.
+// The rank of a node is its expected signed count divided by the sum of the absolute expected counts,
+// so a node that is mostly reached through negative edges has a negative rank
+func (c CSR[T]) SignedPageRank(a, e T, teleport []T) ([]T, error) {
+	n := c.Rows
+	rank, err := c.PersonalizedPageRank(a, e, teleport)
+	if err != nil {
+		return nil, err
+	}
+	p, _ := distribution(n, teleport)
+	signed := make([]T, n)
+	var leak T
+	for i := range n {
+		indices, data := c.Row(i)
+		var out T
+		for _, value := range data {
+			out += abs(finite(value))
+		}
+		if out == 0 {
+			leak += rank[i]
+			continue
+		}
+		for k, index := range indices {
+			signed[index] += rank[i] * finite(data[k]) / out
+		}
+	}
+	var sum T
+	for i := range signed {
+		signed[i] += leak * p[i]
+		sum += abs(signed[i])
+	}
+	if sum > 0 {
+		for i := range signed {
+			signed[i] /= sum
+		}
+	}
+	return signed, nil
+}
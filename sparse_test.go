@@ -0,0 +1,312 @@
+// Copyright 2025 The Morpheus Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/alixaxel/pagerank"
+)
+
+// must panics if the pagerank returned an error
+func must[T Float](ranks []T, err error) []T {
+	if err != nil {
+		panic(err)
+	}
+	return ranks
+}
+
+func TestCSRPageRank(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	const size = 16
+	graph := pagerank.NewGraph()
+	neighbors := make([][]int, size)
+	for i := range neighbors {
+		for ii := range size {
+			if rng.Intn(3) == 0 {
+				neighbors[i] = append(neighbors[i], ii)
+			}
+		}
+	}
+	csr := NewCSR[float64](size, neighbors)
+	for i := range neighbors {
+		indices, data := csr.Row(i)
+		for ii, j := range indices {
+			data[ii] = rng.Float64()
+			graph.Link(uint32(i), uint32(j), data[ii])
+		}
+	}
+	expected := make([]float64, size)
+	graph.Rank(.85, 1e-9, func(node uint32, rank float64) {
+		expected[node] = rank
+	})
+	for i, value := range must(csr.PageRank(.85, 1e-9)) {
+		if math.Abs(value-expected[i]) > 1e-6 {
+			t.Fatalf("%d: %f != %f", i, value, expected[i])
+		}
+	}
+}
+
+func TestCSRPageRank32(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	const size = 16
+	a, b := NewMatrix(size, size, make([]float32, size*size)...), NewMatrix(size, size, make([]float64, size*size)...)
+	for i := range a.Data {
+		if i%size != 3 && rng.Intn(3) == 0 {
+			a.Data[i] = rng.Float32()
+			b.Data[i] = float64(a.Data[i])
+		}
+	}
+	expected := must(FromDense(b).PageRank(.85, 1e-9))
+	for i, value := range must(FromDense(a).PageRank(.85, 1e-6)) {
+		if math.Abs(float64(value)-expected[i]) > 1e-5 {
+			t.Fatalf("%d: %f != %f", i, value, expected[i])
+		}
+	}
+}
+
+// exactPageRank is a dense power iteration pagerank with a teleport distribution that runs to convergence
+func exactPageRank(a float64, adj [][]float64, teleport []float64) []float64 {
+	n := len(adj)
+	sum := 0.0
+	for _, value := range teleport {
+		sum += value
+	}
+	rank := make([]float64, n)
+	for i := range rank {
+		rank[i] = 1 / float64(n)
+	}
+	for range 10000 {
+		next := make([]float64, n)
+		leak := 0.0
+		for i := range adj {
+			out := 0.0
+			for _, value := range adj[i] {
+				out += value
+			}
+			if out == 0 {
+				leak += rank[i]
+				continue
+			}
+			for ii, value := range adj[i] {
+				next[ii] += a * rank[i] * value / out
+			}
+		}
+		for i := range next {
+			next[i] += ((1 - a) + a*leak) * teleport[i] / sum
+		}
+		rank = next
+	}
+	return rank
+}
+
+func TestPersonalizedPageRank(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	const size = 16
+	adj := make([][]float64, size)
+	neighbors := make([][]int, size)
+	for i := range neighbors {
+		adj[i] = make([]float64, size)
+		for ii := range size {
+			if i != 3 && rng.Intn(3) == 0 {
+				neighbors[i] = append(neighbors[i], ii)
+			}
+		}
+	}
+	csr := NewCSR[float64](size, neighbors)
+	for i := range neighbors {
+		indices, data := csr.Row(i)
+		for ii, j := range indices {
+			data[ii] = rng.Float64()
+			adj[i][j] = data[ii]
+		}
+	}
+	teleport := make([]float64, size)
+	teleport[1], teleport[5], teleport[9] = 1, 2, 1
+	expected := exactPageRank(.85, adj, teleport)
+	for i, value := range must(csr.PersonalizedPageRank(.85, 1e-12, teleport)) {
+		if math.Abs(value-expected[i]) > 1e-9 {
+			t.Fatalf("%d: %f != %f", i, value, expected[i])
+		}
+	}
+}
+
+func TestPageRankNegativeEdges(t *testing.T) {
+	// the rows have positive sums so normalizing them by their signed sum as the alixaxel pagerank did is defined
+	signed := [][]float64{
+		{0, 2, -.5, 1},
+		{0, 0, 2, 1},
+		{1, 0, 0, 0},
+		{2, -.5, 1, 0},
+	}
+	n := len(signed)
+	m := NewMatrix(n, n, make([]float64, n*n)...)
+	absolute, uniform := make([][]float64, n), make([]float64, n)
+	for i := range signed {
+		copy(m.Data[i*n:(i+1)*n], signed[i])
+		for _, value := range signed[i] {
+			absolute[i] = append(absolute[i], math.Abs(value))
+		}
+		uniform[i] = 1
+	}
+	raw, magnitude := exactPageRank(.85, signed, uniform), exactPageRank(.85, absolute, uniform)
+	differs := false
+	for i, value := range must(FromDense(m).PageRank(.85, 1e-12)) {
+		if math.Abs(value-magnitude[i]) > 1e-9 {
+			t.Fatalf("%d: %f != %f", i, value, magnitude[i])
+		}
+		differs = differs || math.Abs(value-raw[i]) > 1e-3
+	}
+	if !differs {
+		t.Fatal("the negative edges were normalized by the signed sum")
+	}
+}
+
+func TestPageRankLength(t *testing.T) {
+	graph := NewCSR[float64](3, [][]int{{1}, {2}, {0}})
+	short := []float64{1, 1}
+	if _, err := graph.PersonalizedPageRank(.85, 1e-6, short); !errors.Is(err, ErrLength) {
+		t.Fatalf("teleport: %v is not %v", err, ErrLength)
+	}
+	if _, err := graph.PageRank(.85, 1e-6, short); !errors.Is(err, ErrLength) {
+		t.Fatalf("warm: %v is not %v", err, ErrLength)
+	}
+	if _, err := graph.SignedPageRank(.85, 1e-6, short); !errors.Is(err, ErrLength) {
+		t.Fatalf("signed: %v is not %v", err, ErrLength)
+	}
+	if _, err := graph.PersonalizedPageRank(.85, 1e-6, []float64{1, 1, 1}, []float64{1, 0, 0}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSignedPageRank(t *testing.T) {
+	cycle := NewCSR[float64](2, [][]int{{1}, {0}})
+	cycle.Data[0], cycle.Data[1] = -1, 1
+	for i, value := range must(cycle.SignedPageRank(.85, 1e-12, nil)) {
+		if expected := []float64{.5, -.5}[i]; math.Abs(value-expected) > 1e-9 {
+			t.Fatalf("%d: %f != %f", i, value, expected)
+		}
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	const size = 16
+	adj := NewMatrix(size, size, make([]float64, size*size)...)
+	for i := range adj.Data {
+		if i/size != 3 && rng.Intn(3) == 0 {
+			adj.Data[i] = rng.Float64()
+		}
+	}
+	// without negative edges the signed pagerank is the pagerank advanced by one step
+	graph := FromDense(adj)
+	rank, signed := must(graph.PageRank(.85, 1e-12)), must(graph.SignedPageRank(.85, 1e-12, nil))
+	for i, value := range signed {
+		if expected := (rank[i] - .15/size) / .85; math.Abs(value-expected) > 1e-9 {
+			t.Fatalf("%d: %f != %f", i, value, expected)
+		}
+	}
+}
+
+// sparseSeed is the seed of the iterations of MorpheusSparse, which is drawn after the seed of the search
+func sparseSeed(seed int64) int64 {
+	rng := rand.New(rand.NewSource(seed))
+	rng.Int63()
+	return rng.Int63()
+}
+
+func TestMorpheusSparse(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	type T struct{}
+	vectors := normal[T](rng, 16, 8)
+	teleport := make([]float64, len(vectors))
+	for i := range teleport {
+		teleport[i] = float64(i%4 + 1)
+	}
+	for _, ranker := range []Ranker{nil, GraphRanker{Teleport: teleport}, GraphRanker{Signed: true}} {
+		config := Config{
+			Iterations: 8,
+			Size:       8,
+			Divider:    1,
+			Ranker:     ranker,
+		}
+		dense := Morpheus(sparseSeed(1), config, vectors)
+		config.Neighbors = len(vectors) - 1
+		sparse := MorpheusSparse(1, config, vectors)
+		for i := range vectors {
+			if math.Abs(dense.Avg[i]-sparse.Avg[i]) > 1e-9 {
+				t.Fatalf("%v avg %d: %f != %f", ranker, i, dense.Avg[i], sparse.Avg[i])
+			}
+			for ii := range vectors {
+				if math.Abs(dense.Cov[i][ii]-sparse.Sparse.At(i, ii)) > 1e-12 {
+					t.Fatalf("%v cov %d %d: %f != %f", ranker, i, ii, dense.Cov[i][ii], sparse.Sparse.At(i, ii))
+				}
+			}
+		}
+	}
+
+	config := Config{
+		Iterations: 8,
+		Size:       8,
+		Divider:    1,
+		Ranker:     HITSRanker{},
+	}
+	if _, err := MorpheusSparseChecked(1, config, vectors); !errors.Is(err, ErrRanker) {
+		t.Fatalf("%v is not %v", err, ErrRanker)
+	}
+	config.Ranker = nil
+	config.Neighbors = 4
+	config.Search = LSHSearch{Bits: 4, Tables: 4}
+	sparse := MorpheusSparse(1, config, vectors)
+	for i := range vectors {
+		indices, _ := sparse.Sparse.Row(i)
+		if len(indices) == 0 || len(indices) > 5 || indices[0] != i {
+			t.Fatalf("%d: bad neighbors %v", i, indices)
+		}
+	}
+}
+
+func TestLSHSearch(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	const (
+		clusters = 8
+		size     = 32
+		points   = 64
+	)
+	centers := NewMatrix(size, clusters, make([]float32, size*clusters)...)
+	for i := range centers.Data {
+		centers.Data[i] = float32(rng.NormFloat64())
+	}
+	x := NewMatrix(size, clusters*points, make([]float32, size*clusters*points)...)
+	for i := range x.Rows {
+		center := centers.Data[(i%clusters)*size : (i%clusters+1)*size]
+		for ii := range size {
+			x.Data[i*size+ii] = center[ii] + .1*float32(rng.NormFloat64())
+		}
+	}
+	x = x.Unit()
+	const k = 8
+	exact := BlockedSearch{Block: 100}.Search(rng, x, k)
+	approximate := LSHSearch{}.Search(rng, x, k)
+	found, total := 0, 0
+	for i := range exact {
+		for _, a := range exact[i] {
+			if a%clusters != i%clusters {
+				t.Fatalf("%d: %d is in another cluster", i, a)
+			}
+			for _, b := range approximate[i] {
+				if a == b {
+					found++
+					break
+				}
+			}
+			total++
+		}
+	}
+	if recall := float64(found) / float64(total); recall < .5 {
+		t.Fatalf("recall %f is too low", recall)
+	}
+}
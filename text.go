@@ -178,7 +178,8 @@ func TextMode() {
 			Size:       size,
 			Divider:    8,
 		}
-		cov := Morpheus(seed, config, lines)
+		result := Morpheus(seed, config, lines)
+		cov := result.Cov
 		v := make([]float64, len(cov))
 		for i := range cov {
 			for _, value := range cov[i] {
@@ -213,13 +214,13 @@ func TextMode() {
 
 		sum = 0.0
 		for i := 0; i < len(lines)-count; i++ {
-			sum += lines[i].Avg
+			sum += result.Avg[i]
 		}
-		sum += lines[(len(lines)-count)+index].Avg
+		sum += result.Avg[(len(lines)-count)+index]
 
 		return Trace{
 			Trace: string(next),
-			Value: lines[(len(lines)-count)+index].Avg / sum,
+			Value: result.Avg[(len(lines)-count)+index] / sum,
 		}
 	}
 
@@ -237,7 +238,7 @@ func TextMode() {
 		done <- t
 	}
 
-	rng := rand.New(rand.NewSource(1))
+	rng := rand.New(rand.NewSource(*FlagSeed))
 	state := "What is the meaning of life?"
 	for range segments {
 		traces := make([]Trace, 0, samples)
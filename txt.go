@@ -130,16 +130,10 @@ func TxtMode() {
 						markov[i][ii], state = state, value
 					}
 				}
-				for _, line := range lines {
-					if line != nil {
-						line.Avg = 0
-						line.Stddev = 0
-					}
-				}
 				if index < len(lines) {
 					continue
 				}
-				embedding := Morpheus(rng.Int63(), config, lines)
+				embedding := Morpheus(rng.Int63(), config, lines).Cov
 				rows := len(embedding)
 				cols := len(embedding[0])
 				mat := NewMatrix(rows*cols, 1, make([]float32, rows*cols)...)
@@ -166,7 +160,7 @@ func TxtMode() {
 			}
 			done <- true
 		}
-		rng := rand.New(rand.NewSource(1))
+		rng := rand.New(rand.NewSource(*FlagSeed))
 		for _, file := range files {
 			go learn(file, rng.Int63())
 		}
@@ -177,7 +171,7 @@ func TxtMode() {
 	}
 
 	fmt.Println(*FlagPrompt)
-	rng := rand.New(rand.NewSource(1))
+	rng := rand.New(rand.NewSource(*FlagSeed))
 	for i := range files {
 		input, err := os.Open(fmt.Sprintf("%s.v", files[i].Name))
 		if err != nil {
@@ -237,13 +231,7 @@ func TxtMode() {
 				break
 			}
 		}
-		for _, line := range lines {
-			if line != nil {
-				line.Avg = 0
-				line.Stddev = 0
-			}
-		}
-		embedding := Morpheus(rng.Int63(), config, lines)
+		embedding := Morpheus(rng.Int63(), config, lines).Cov
 		rows := len(embedding)
 		cols := len(embedding[0])
 		mat := NewMatrix(rows*cols, 1, make([]float32, rows*cols)...)
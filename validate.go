@@ -0,0 +1,158 @@
+// Copyright 2025 The Morpheus Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	// ErrEmpty is returned when there are no vectors
+	ErrEmpty = errors.New("no vectors")
+	// ErrIterations is returned when there are no iterations
+	ErrIterations = errors.New("iterations must be positive")
+	// ErrDimension is returned when a vector is missing or has more than config.Size values
+	ErrDimension = errors.New("vector length doesn't match size")
+	// ErrNonFinite is returned when a vector has a NaN or infinite value
+	ErrNonFinite = errors.New("non finite value")
+	// ErrDivider is returned when the divider produces projection matrices without rows
+	ErrDivider = errors.New("divider produces zero rows")
+	// ErrLength is returned when a teleport distribution or a warm rank doesn't have a value for every node
+	ErrLength = errors.New("length doesn't match the number of nodes")
+	// ErrKernel is returned when sparse mode is given a similarity that isn't a Kernel
+	ErrKernel = errors.New("sparse mode needs a kernel similarity")
+	// ErrRanker is returned when a mode can't run the ranker, sparse mode only runs a GraphRanker
+	ErrRanker = errors.New("ranker isn't supported")
+	// ErrConstraint is returned when the edge constraints don't cover the vectors
+	ErrConstraint = errors.New("constraints don't cover the vectors")
+)
+
+// Validate checks the config and the vectors before a run, vectors shorter than config.Size are zero padded
+func Validate[T any](config Config, vectors []*Vector[T]) error {
+	if len(vectors) == 0 {
+		return ErrEmpty
+	}
+	if config.Iterations < 0 || config.Iterations == 0 && config.Convergence <= 0 {
+		return fmt.Errorf("%w: %d", ErrIterations, config.Iterations)
+	}
+	if err := validateVectors(config, vectors); err != nil {
+		return err
+	}
+	projection, _, _, ranker := config.parts()
+	if _, rows := config.shape(projection); config.Divider < 0 || rows <= 0 {
+		return fmt.Errorf("%w: divider %d with size %d", ErrDivider, config.Divider, config.Size)
+	}
+	if length, ok := teleportLength(ranker); ok && length != len(vectors) {
+		return fmt.Errorf("%w: teleport has %d values for %d vectors", ErrLength, length, len(vectors))
+	}
+	return nil
+}
+
+// teleportLength returns the length of the teleport distribution of the ranker if it has one
+func teleportLength(ranker Ranker) (int, bool) {
+	switch r := ranker.(type) {
+	case GraphRanker:
+		return len(r.Teleport), r.Teleport != nil
+	case *AccumulatingRanker:
+		return len(r.Teleport), r.Teleport != nil
+	case CountingRanker:
+		return len(r.Teleport), r.Teleport != nil
+	}
+	return 0, false
+}
+
+// validateVectors checks that there are vectors and that they fit in config.Size and are finite
+func validateVectors[T any](config Config, vectors []*Vector[T]) error {
+	if len(vectors) == 0 {
+		return ErrEmpty
+	}
+	if config.Size <= 0 {
+		return fmt.Errorf("%w: size is %d", ErrDimension, config.Size)
+	}
+	for i, vector := range vectors {
+		if vector == nil {
+			return fmt.Errorf("%w: vector %d is nil", ErrDimension, i)
+		}
+		if len(vector.Vector) > config.Size {
+			return fmt.Errorf("%w: vector %d has length %d and size is %d", ErrDimension, i, len(vector.Vector), config.Size)
+		}
+		for ii, value := range vector.Vector {
+			if math.IsNaN(float64(value)) || math.IsInf(float64(value), 0) {
+				return fmt.Errorf("%w: vector %d value %d is %f", ErrNonFinite, i, ii, value)
+			}
+		}
+	}
+	return nil
+}
+
+// isKernel returns true if the similarity is a Kernel
+func isKernel(similarity Similarity) bool {
+	_, ok := similarity.(Kernel)
+	return ok
+}
+
+// MorpheusChecked is Morpheus that validates its input and returns an error instead of panicking
+func MorpheusChecked[T any](seed int64, config Config, vectors []*Vector[T], mutate ...func(cs *Matrix[float32])) (Result, error) {
+	if err := Validate(config, vectors); err != nil {
+		return Result{}, err
+	}
+	return Morpheus(seed, config, vectors, mutate...), nil
+}
+
+// MorpheusSparseChecked is MorpheusSparse that validates its input and returns an error instead of panicking
+func MorpheusSparseChecked[T any](seed int64, config Config, vectors []*Vector[T]) (Result, error) {
+	if err := Validate(config, vectors); err != nil {
+		return Result{}, err
+	}
+	_, similarity, _, ranker := config.parts()
+	if !isKernel(similarity) {
+		return Result{}, ErrKernel
+	}
+	if _, ok := ranker.(GraphRanker); !ok {
+		return Result{}, ErrRanker
+	}
+	return MorpheusSparse(seed, config, vectors), nil
+}
+
+// MorpheusGramSchmidtChecked is MorpheusGramSchmidt that validates its input and returns an error instead of panicking
+func MorpheusGramSchmidtChecked[T any](seed int64, config Config, vectors []*Vector[T], mutate ...func(cs *Matrix[float32])) (Result, error) {
+	checked := config
+	checked.Projection = GramSchmidtProjection{}
+	if err := Validate(checked, vectors); err != nil {
+		return Result{}, err
+	}
+	return MorpheusGramSchmidt(seed, config, vectors, mutate...), nil
+}
+
+// Morpheus2Checked is Morpheus2 that validates its input and returns an error instead of panicking
+func Morpheus2Checked[T any](seed int64, config Config, vectors []*Vector[T], g map[string]map[string]uint64) (Result, error) {
+	checked := config
+	checked.Projection = SoftmaxProjection{}
+	if err := Validate(checked, vectors); err != nil {
+		return Result{}, err
+	}
+	return Morpheus2(seed, config, vectors, g), nil
+}
+
+// Morpheus3Checked is Morpheus3 that validates its input and returns an error instead of panicking
+func Morpheus3Checked[T any](seed int64, config Config, vectors []*Vector[T]) (Result, error) {
+	checked := config
+	checked.Projection, checked.Ranker = SoftmaxProjection{}, &AccumulatingRanker{}
+	if err := Validate(checked, vectors); err != nil {
+		return Result{}, err
+	}
+	return Morpheus3(seed, config, vectors), nil
+}
+
+// MorpheusMarkovChecked is MorpheusMarkov that validates its input and returns an error instead of panicking,
+// the markov model doesn't iterate so only the vectors are checked
+func MorpheusMarkovChecked[T any, F Float](seed int64, config Config, vectors []*Vector[T]) (Matrix[F], error) {
+	if err := validateVectors(config, vectors); err != nil {
+		return Matrix[F]{}, err
+	}
+	return MorpheusMarkov[T, F](seed, config, vectors), nil
+}
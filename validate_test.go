@@ -0,0 +1,117 @@
+// Copyright 2025 The Morpheus Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	type T struct{}
+	vectors := func(values ...float32) []*Vector[T] {
+		return []*Vector[T]{
+			{Vector: []float32{1, 2, 3, 4}},
+			{Vector: values},
+		}
+	}
+	good := Config{
+		Iterations: 4,
+		Size:       4,
+		Divider:    1,
+	}
+	tests := []struct {
+		name    string
+		config  func(c Config) Config
+		vectors []*Vector[T]
+		err     error
+	}{
+		{"valid", func(c Config) Config { return c }, vectors(4, 3, 2, 1), nil},
+		{"empty", func(c Config) Config { return c }, nil, ErrEmpty},
+		{"iterations", func(c Config) Config { c.Iterations = 0; return c }, vectors(4, 3, 2, 1), ErrIterations},
+		{"negative iterations", func(c Config) Config { c.Iterations = -1; c.Convergence = 1e-3; return c }, vectors(4, 3, 2, 1), ErrIterations},
+		{"adaptive", func(c Config) Config { c.Iterations = 0; c.Convergence = 1e-3; return c }, vectors(4, 3, 2, 1), nil},
+		{"short", func(c Config) Config { return c }, vectors(4, 3, 2), nil},
+		{"long", func(c Config) Config { c.Size = 3; return c }, vectors(4, 3, 2), ErrDimension},
+		{"nil", func(c Config) Config { return c }, []*Vector[T]{nil}, ErrDimension},
+		{"nan", func(c Config) Config { return c }, vectors(4, float32(math.NaN()), 2, 1), ErrNonFinite},
+		{"inf", func(c Config) Config { return c }, vectors(4, 3, float32(math.Inf(-1)), 1), ErrNonFinite},
+		{"divider", func(c Config) Config { c.Divider = 16; return c }, vectors(4, 3, 2, 1), ErrDivider},
+		{"negative divider", func(c Config) Config { c.Divider = -1; return c }, vectors(4, 3, 2, 1), ErrDivider},
+		{"teleport", func(c Config) Config { c.Ranker = GraphRanker{Teleport: []float64{1}}; return c }, vectors(4, 3, 2, 1), ErrLength},
+		{"counting teleport", func(c Config) Config { c.Ranker = CountingRanker{Teleport: []float64{1}}; return c }, vectors(4, 3, 2, 1), ErrLength},
+		{"counting", func(c Config) Config { c.Ranker = CountingRanker{Accuracy: 8, Teleport: []float64{1, 0}}; return c }, vectors(4, 3, 2, 1), nil},
+	}
+	for _, test := range tests {
+		_, err := MorpheusChecked(1, test.config(good), test.vectors)
+		if !errors.Is(err, test.err) {
+			t.Fatalf("%s: %v is not %v", test.name, err, test.err)
+		}
+	}
+
+	// the presets are checked too
+	presets := []struct {
+		name string
+		run  func(config Config, vectors []*Vector[T]) error
+	}{
+		{"gram schmidt", func(config Config, vectors []*Vector[T]) error {
+			_, err := MorpheusGramSchmidtChecked(1, config, vectors)
+			return err
+		}},
+		{"2", func(config Config, vectors []*Vector[T]) error {
+			_, err := Morpheus2Checked(1, config, vectors, nil)
+			return err
+		}},
+		{"3", func(config Config, vectors []*Vector[T]) error {
+			_, err := Morpheus3Checked(1, config, vectors)
+			return err
+		}},
+		{"markov", func(config Config, vectors []*Vector[T]) error {
+			_, err := MorpheusMarkovChecked[T, float32](1, config, vectors)
+			return err
+		}},
+	}
+	for _, preset := range presets {
+		for _, test := range tests {
+			if preset.name == "markov" && (errors.Is(test.err, ErrIterations) || errors.Is(test.err, ErrDivider)) {
+				// the markov model doesn't iterate or project
+				continue
+			}
+			if (preset.name == "3" || preset.name == "markov") && errors.Is(test.err, ErrLength) {
+				// the ranker isn't used
+				continue
+			}
+			if err := preset.run(test.config(good), test.vectors); !errors.Is(err, test.err) {
+				t.Fatalf("%s %s: %v is not %v", preset.name, test.name, err, test.err)
+			}
+		}
+	}
+
+	func() {
+		defer func() {
+			if err, _ := recover().(error); !errors.Is(err, ErrIterations) {
+				t.Fatalf("%v is not %v", err, ErrIterations)
+			}
+		}()
+		config := good
+		config.Iterations = 0
+		Morpheus(1, config, vectors(4, 3, 2, 1))
+	}()
+
+	config := good
+	config.Similarity = similarityFunc(Cosine{}.Similarity)
+	if _, err := MorpheusSparseChecked(1, config, vectors(4, 3, 2, 1)); !errors.Is(err, ErrKernel) {
+		t.Fatalf("%v is not %v", err, ErrKernel)
+	}
+}
+
+// similarityFunc is a similarity that isn't a kernel
+type similarityFunc func(x, y Matrix[float32]) Matrix[float32]
+
+// Similarity calls the function
+func (s similarityFunc) Similarity(x, y Matrix[float32]) Matrix[float32] {
+	return s(x, y)
+}
@@ -2,16 +2,80 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-//go:build (!noasm && arm) || (!noasm && arm64)
-// +build !noasm,arm !noasm,arm64
+//go:build !noasm && arm64
+// +build !noasm,arm64
 
 package vector
 
 import (
 	"unsafe"
+
+	"golang.org/x/sys/cpu"
 )
 
+// simd is set if the cpu has the advanced simd instructions of the kernels,
+// the reference loops are used otherwise
+var simd = cpu.ARM64.HasASIMD
+
+// Dot computes the dot product of two float32 vectors of the same length
 func Dot(x, y []float32) (z float32) {
-	vdot(unsafe.Pointer(&x[0]), unsafe.Pointer(&y[0]), unsafe.Pointer(uintptr(len(x))), unsafe.Pointer(&z))
+	equal(x, y)
+	// the kernel only initializes its sum for at least 4 values
+	if !simd || len(x) < 4 {
+		return dot(x, y)
+	}
+	vdot(unsafe.Pointer(&x[0]), unsafe.Pointer(&y[0]), int64(len(x)), unsafe.Pointer(&z))
 	return z
 }
+
+func vdot64(x, y []float64) float64 {
+	if !simd {
+		return dot64(x, y)
+	}
+	return dot64NEON(x, y)
+}
+
+func vaxpy64(a float64, x, y []float64) {
+	if !simd {
+		axpy64(a, x, y)
+		return
+	}
+	axpy64NEON(a, x, y)
+}
+
+func vscale64(a float64, x []float64) {
+	if !simd {
+		scale64(a, x)
+		return
+	}
+	scale64NEON(a, x)
+}
+
+func vsum64(x []float64) float64 {
+	if !simd {
+		return sum64(x)
+	}
+	return sum64NEON(x)
+}
+
+func vmax64(x []float64) float64 {
+	if !simd {
+		return max64(x)
+	}
+	return max64NEON(x)
+}
+
+//go:noescape
+func dot64NEON(x, y []float64) float64
+
+//go:noescape
+func axpy64NEON(a float64, x, y []float64)
+
+//go:noescape
+func scale64NEON(a float64, x []float64)
+
+//go:noescape
+func sum64NEON(x []float64) float64
+
+//go:noescape
+func max64NEON(x []float64) float64
@@ -2,8 +2,8 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-//go:build (!noasm && arm) || (!noasm && arm64)
-// +build !noasm,arm !noasm,arm64
+//go:build !noasm && arm64
+// +build !noasm,arm64
 
 package vector
 
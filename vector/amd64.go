@@ -9,9 +9,73 @@ package vector
 
 import (
 	"unsafe"
+
+	"golang.org/x/sys/cpu"
 )
 
+// simd is set if the cpu has the AVX2 and FMA instructions of the kernels,
+// the reference loops are used otherwise
+var simd = cpu.X86.HasAVX2 && cpu.X86.HasFMA
+
+// Dot computes the dot product of two float32 vectors of the same length
 func Dot(x, y []float32) (z float32) {
-	_mm256_dot(unsafe.Pointer(&x[0]), unsafe.Pointer(&y[0]), unsafe.Pointer(uintptr(len(x))), unsafe.Pointer(&z))
+	equal(x, y)
+	// the kernel only initializes its sum for at least 8 values
+	if !simd || len(x) < 8 {
+		return dot(x, y)
+	}
+	_mm256_dot(unsafe.Pointer(&x[0]), unsafe.Pointer(&y[0]), int64(len(x)), unsafe.Pointer(&z))
 	return z
 }
+
+func vdot64(x, y []float64) float64 {
+	if !simd {
+		return dot64(x, y)
+	}
+	return dot64AVX(x, y)
+}
+
+func vaxpy64(a float64, x, y []float64) {
+	if !simd {
+		axpy64(a, x, y)
+		return
+	}
+	axpy64AVX(a, x, y)
+}
+
+func vscale64(a float64, x []float64) {
+	if !simd {
+		scale64(a, x)
+		return
+	}
+	scale64AVX(a, x)
+}
+
+func vsum64(x []float64) float64 {
+	if !simd {
+		return sum64(x)
+	}
+	return sum64AVX(x)
+}
+
+func vmax64(x []float64) float64 {
+	if !simd {
+		return max64(x)
+	}
+	return max64AVX(x)
+}
+
+//go:noescape
+func dot64AVX(x, y []float64) float64
+
+//go:noescape
+func axpy64AVX(a float64, x, y []float64)
+
+//go:noescape
+func scale64AVX(a float64, x []float64)
+
+//go:noescape
+func sum64AVX(x []float64) float64
+
+//go:noescape
+func max64AVX(x []float64) float64
@@ -0,0 +1,89 @@
+// Copyright 2025 The Morpheus Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"math"
+)
+
+// Dot64 computes the dot product of two float64 vectors of the same length
+func Dot64(x, y []float64) float64 {
+	equal(x, y)
+	return vdot64(x, y)
+}
+
+// Axpy64 adds a times x to y, x and y have the same length
+func Axpy64(a float64, x, y []float64) {
+	equal(x, y)
+	vaxpy64(a, x, y)
+}
+
+// Scale64 multiplies x by a
+func Scale64(a float64, x []float64) {
+	vscale64(a, x)
+}
+
+// Sum64 computes the sum of x
+func Sum64(x []float64) float64 {
+	return vsum64(x)
+}
+
+// Norm64 computes the l2 norm of x
+func Norm64(x []float64) float64 {
+	return math.Sqrt(vdot64(x, x))
+}
+
+// ArgMax64 returns the index of the first largest value of x ignoring NaN,
+// or -1 if x is empty or only NaN
+func ArgMax64(x []float64) int {
+	max := vmax64(x)
+	for i, value := range x {
+		if value == max {
+			return i
+		}
+	}
+	return -1
+}
+
+// dot64 is the reference dot product
+func dot64(x, y []float64) (z float64) {
+	for i := range x {
+		z += x[i] * y[i]
+	}
+	return z
+}
+
+// axpy64 is the reference axpy
+func axpy64(a float64, x, y []float64) {
+	for i := range x {
+		y[i] += a * x[i]
+	}
+}
+
+// scale64 is the reference scale
+func scale64(a float64, x []float64) {
+	for i := range x {
+		x[i] *= a
+	}
+}
+
+// sum64 is the reference sum
+func sum64(x []float64) (z float64) {
+	for _, value := range x {
+		z += value
+	}
+	return z
+}
+
+// max64 is the reference maximum that ignores NaN, it is -Inf for an empty x
+func max64(x []float64) float64 {
+	z := math.Inf(-1)
+	for _, value := range x {
+		if value > z {
+			z = value
+		}
+	}
+	return z
+}
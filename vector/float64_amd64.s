@@ -0,0 +1,183 @@
+// Copyright 2025 The Morpheus Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !noasm
+
+#include "textflag.h"
+
+// func dot64AVX(x, y []float64) float64
+TEXT ·dot64AVX(SB), NOSPLIT, $0-56
+	MOVQ   x_base+0(FP), SI
+	MOVQ   x_len+8(FP), CX
+	MOVQ   y_base+24(FP), DI
+	VXORPD Y0, Y0, Y0
+	VXORPD Y1, Y1, Y1
+	MOVQ   CX, BX
+	ANDQ   $-8, BX
+	XORQ   AX, AX
+
+dot8:
+	CMPQ        AX, BX
+	JGE         dotreduce
+	VMOVUPD     (SI)(AX*8), Y2
+	VMOVUPD     32(SI)(AX*8), Y3
+	VFMADD231PD (DI)(AX*8), Y2, Y0
+	VFMADD231PD 32(DI)(AX*8), Y3, Y1
+	ADDQ        $8, AX
+	JMP         dot8
+
+dotreduce:
+	VADDPD       Y1, Y0, Y0
+	VEXTRACTF128 $1, Y0, X1
+	VADDPD       X1, X0, X0
+	VHADDPD      X0, X0, X0
+
+dot1:
+	CMPQ        AX, CX
+	JGE         dotdone
+	VMOVSD      (SI)(AX*8), X2
+	VFMADD231SD (DI)(AX*8), X2, X0
+	INCQ        AX
+	JMP         dot1
+
+dotdone:
+	VZEROUPPER
+	MOVSD X0, ret+48(FP)
+	RET
+
+// func axpy64AVX(a float64, x, y []float64)
+TEXT ·axpy64AVX(SB), NOSPLIT, $0-56
+	VBROADCASTSD a+0(FP), Y0
+	MOVQ         x_base+8(FP), SI
+	MOVQ         x_len+16(FP), CX
+	MOVQ         y_base+32(FP), DI
+	MOVQ         CX, BX
+	ANDQ         $-4, BX
+	XORQ         AX, AX
+
+axpy4:
+	CMPQ        AX, BX
+	JGE         axpy1
+	VMOVUPD     (SI)(AX*8), Y1
+	VFMADD213PD (DI)(AX*8), Y0, Y1
+	VMOVUPD     Y1, (DI)(AX*8)
+	ADDQ        $4, AX
+	JMP         axpy4
+
+axpy1:
+	CMPQ        AX, CX
+	JGE         axpydone
+	VMOVSD      (SI)(AX*8), X1
+	VFMADD213SD (DI)(AX*8), X0, X1
+	VMOVSD      X1, (DI)(AX*8)
+	INCQ        AX
+	JMP         axpy1
+
+axpydone:
+	VZEROUPPER
+	RET
+
+// func scale64AVX(a float64, x []float64)
+TEXT ·scale64AVX(SB), NOSPLIT, $0-32
+	VBROADCASTSD a+0(FP), Y0
+	MOVQ         x_base+8(FP), SI
+	MOVQ         x_len+16(FP), CX
+	MOVQ         CX, BX
+	ANDQ         $-4, BX
+	XORQ         AX, AX
+
+scale4:
+	CMPQ    AX, BX
+	JGE     scale1
+	VMULPD  (SI)(AX*8), Y0, Y1
+	VMOVUPD Y1, (SI)(AX*8)
+	ADDQ    $4, AX
+	JMP     scale4
+
+scale1:
+	CMPQ   AX, CX
+	JGE    scaledone
+	VMULSD (SI)(AX*8), X0, X1
+	VMOVSD X1, (SI)(AX*8)
+	INCQ   AX
+	JMP    scale1
+
+scaledone:
+	VZEROUPPER
+	RET
+
+// func sum64AVX(x []float64) float64
+TEXT ·sum64AVX(SB), NOSPLIT, $0-32
+	MOVQ   x_base+0(FP), SI
+	MOVQ   x_len+8(FP), CX
+	VXORPD Y0, Y0, Y0
+	VXORPD Y1, Y1, Y1
+	MOVQ   CX, BX
+	ANDQ   $-8, BX
+	XORQ   AX, AX
+
+sum8:
+	CMPQ   AX, BX
+	JGE    sumreduce
+	VADDPD (SI)(AX*8), Y0, Y0
+	VADDPD 32(SI)(AX*8), Y1, Y1
+	ADDQ   $8, AX
+	JMP    sum8
+
+sumreduce:
+	VADDPD       Y1, Y0, Y0
+	VEXTRACTF128 $1, Y0, X1
+	VADDPD       X1, X0, X0
+	VHADDPD      X0, X0, X0
+
+sum1:
+	CMPQ   AX, CX
+	JGE    sumdone
+	VADDSD (SI)(AX*8), X0, X0
+	INCQ   AX
+	JMP    sum1
+
+sumdone:
+	VZEROUPPER
+	MOVSD X0, ret+24(FP)
+	RET
+
+// func max64AVX(x []float64) float64
+// the running maximum is the second source of VMAXPD, so a NaN in x is ignored
+TEXT ·max64AVX(SB), NOSPLIT, $0-32
+	MOVQ         x_base+0(FP), SI
+	MOVQ         x_len+8(FP), CX
+	MOVQ         $0xFFF0000000000000, DX
+	MOVQ         DX, X0
+	VBROADCASTSD X0, Y0
+	MOVQ         CX, BX
+	ANDQ         $-4, BX
+	XORQ         AX, AX
+
+max4:
+	CMPQ    AX, BX
+	JGE     maxreduce
+	VMOVUPD (SI)(AX*8), Y1
+	VMAXPD  Y0, Y1, Y0
+	ADDQ    $4, AX
+	JMP     max4
+
+maxreduce:
+	VEXTRACTF128 $1, Y0, X1
+	VMAXPD       X1, X0, X0
+	VPERMILPD    $1, X0, X1
+	VMAXSD       X1, X0, X0
+
+max1:
+	CMPQ   AX, CX
+	JGE    maxdone
+	VMOVSD (SI)(AX*8), X1
+	VMAXSD X0, X1, X0
+	INCQ   AX
+	JMP    max1
+
+maxdone:
+	VZEROUPPER
+	MOVSD X0, ret+24(FP)
+	RET
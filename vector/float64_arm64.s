@@ -0,0 +1,172 @@
+// Copyright 2025 The Morpheus Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !noasm
+
+#include "textflag.h"
+
+// func dot64NEON(x, y []float64) float64
+TEXT ·dot64NEON(SB), NOSPLIT, $0-56
+	MOVD x_base+0(FP), R0
+	MOVD x_len+8(FP), R2
+	MOVD y_base+24(FP), R1
+	VEOR V0.B16, V0.B16, V0.B16
+	VEOR V1.B16, V1.B16, V1.B16
+	AND  $-4, R2, R3
+	AND  $3, R2
+
+dot4:
+	CBZ    R3, dotreduce
+	VLD1.P 32(R0), [V2.D2, V3.D2]
+	VLD1.P 32(R1), [V4.D2, V5.D2]
+	VFMLA  V2.D2, V4.D2, V0.D2
+	VFMLA  V3.D2, V5.D2, V1.D2
+	SUB    $4, R3
+	B      dot4
+
+dotreduce:
+	VFADD V1.D2, V0.D2, V0.D2
+	VMOV  V0.D[1], R4
+	FMOVD R4, F1
+	FADDD F1, F0
+
+dot1:
+	CBZ     R2, dotdone
+	FMOVD.P 8(R0), F2
+	FMOVD.P 8(R1), F3
+	FMULD   F2, F3
+	FADDD   F3, F0
+	SUB     $1, R2
+	B       dot1
+
+dotdone:
+	FMOVD F0, ret+48(FP)
+	RET
+
+// func axpy64NEON(a float64, x, y []float64)
+TEXT ·axpy64NEON(SB), NOSPLIT, $0-56
+	FMOVD a+0(FP), F0
+	MOVD  x_base+8(FP), R0
+	MOVD  x_len+16(FP), R2
+	MOVD  y_base+32(FP), R1
+	VDUP  V0.D[0], V0.D2
+	AND   $-4, R2, R3
+	AND   $3, R2
+
+axpy4:
+	CBZ    R3, axpy1
+	VLD1.P 32(R0), [V1.D2, V2.D2]
+	VLD1   (R1), [V3.D2, V4.D2]
+	VFMLA  V1.D2, V0.D2, V3.D2
+	VFMLA  V2.D2, V0.D2, V4.D2
+	VST1.P [V3.D2, V4.D2], 32(R1)
+	SUB    $4, R3
+	B      axpy4
+
+axpy1:
+	CBZ     R2, axpydone
+	FMOVD.P 8(R0), F1
+	FMOVD   (R1), F2
+	FMULD   F0, F1
+	FADDD   F1, F2
+	FMOVD.P F2, 8(R1)
+	SUB     $1, R2
+	B       axpy1
+
+axpydone:
+	RET
+
+// func scale64NEON(a float64, x []float64)
+TEXT ·scale64NEON(SB), NOSPLIT, $0-32
+	FMOVD a+0(FP), F0
+	MOVD  x_base+8(FP), R0
+	MOVD  x_len+16(FP), R2
+	VDUP  V0.D[0], V0.D2
+	AND   $-4, R2, R3
+	AND   $3, R2
+
+scale4:
+	CBZ    R3, scale1
+	VLD1   (R0), [V1.D2, V2.D2]
+	VFMUL  V0.D2, V1.D2, V1.D2
+	VFMUL  V0.D2, V2.D2, V2.D2
+	VST1.P [V1.D2, V2.D2], 32(R0)
+	SUB    $4, R3
+	B      scale4
+
+scale1:
+	CBZ     R2, scaledone
+	FMOVD   (R0), F1
+	FMULD   F0, F1
+	FMOVD.P F1, 8(R0)
+	SUB     $1, R2
+	B       scale1
+
+scaledone:
+	RET
+
+// func sum64NEON(x []float64) float64
+TEXT ·sum64NEON(SB), NOSPLIT, $0-32
+	MOVD x_base+0(FP), R0
+	MOVD x_len+8(FP), R2
+	VEOR V0.B16, V0.B16, V0.B16
+	VEOR V1.B16, V1.B16, V1.B16
+	AND  $-4, R2, R3
+	AND  $3, R2
+
+sum4:
+	CBZ    R3, sumreduce
+	VLD1.P 32(R0), [V2.D2, V3.D2]
+	VFADD  V2.D2, V0.D2, V0.D2
+	VFADD  V3.D2, V1.D2, V1.D2
+	SUB    $4, R3
+	B      sum4
+
+sumreduce:
+	VFADD V1.D2, V0.D2, V0.D2
+	VMOV  V0.D[1], R4
+	FMOVD R4, F1
+	FADDD F1, F0
+
+sum1:
+	CBZ     R2, sumdone
+	FMOVD.P 8(R0), F2
+	FADDD   F2, F0
+	SUB     $1, R2
+	B       sum1
+
+sumdone:
+	FMOVD F0, ret+24(FP)
+	RET
+
+// func max64NEON(x []float64) float64
+// FMAXNM returns the number when one of its operands is NaN, so a NaN in x is ignored
+TEXT ·max64NEON(SB), NOSPLIT, $0-32
+	MOVD $0xFFF0000000000000, R4
+	MOVD x_base+0(FP), R0
+	MOVD x_len+8(FP), R2
+	VDUP R4, V0.D2
+	AND  $-2, R2, R3
+	AND  $1, R2
+
+max2:
+	CBZ     R3, maxreduce
+	VLD1.P  16(R0), [V1.D2]
+	VFMAXNM V1.D2, V0.D2, V0.D2
+	SUB     $2, R3
+	B       max2
+
+maxreduce:
+	VMOV    V0.D[1], R4
+	FMOVD   R4, F1
+	FMAXNMD F1, F0
+
+max1:
+	CBZ     R2, maxdone
+	FMOVD.P 8(R0), F1
+	FMAXNMD F1, F0
+
+maxdone:
+	FMOVD F0, ret+24(FP)
+	RET
@@ -0,0 +1,104 @@
+// Copyright 2025 The Morpheus Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// lengths are the lengths of the parity tests, they cover every remainder of the unrolled loops
+var lengths = []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 15, 16, 17, 31, 33, 1023, Size}
+
+// random draws a random vector
+func random(rng *rand.Rand, n int) []float64 {
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = rng.NormFloat64()
+	}
+	return x
+}
+
+// near checks that the kernel and the reference loop agree up to the rounding of the summation order
+func near(t *testing.T, name string, n int, a, b, scale float64) {
+	t.Helper()
+	if math.Abs(a-b) > 1e-12*math.Max(1, scale) {
+		t.Fatalf("%s %d: %g != %g", name, n, a, b)
+	}
+}
+
+func TestFloat64(t *testing.T) {
+	paths(t, testFloat64)
+}
+
+func testFloat64(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, n := range lengths {
+		x, y := random(rng, n), random(rng, n)
+		scale := dot64(x, x) + dot64(y, y)
+		near(t, "dot", n, Dot64(x, y), dot64(x, y), scale)
+		near(t, "sum", n, Sum64(x), sum64(x), scale)
+		near(t, "norm", n, Norm64(x), math.Sqrt(dot64(x, x)), scale)
+
+		a, b := append([]float64{}, y...), append([]float64{}, y...)
+		Axpy64(.5, x, a)
+		axpy64(.5, x, b)
+		for i := range a {
+			near(t, "axpy", n, a[i], b[i], 1)
+		}
+		Scale64(-3, a)
+		scale64(-3, b)
+		for i := range a {
+			near(t, "scale", n, a[i], b[i], 1)
+		}
+
+		if max := max64(x); ArgMax64(x) != -1 && x[ArgMax64(x)] != max {
+			t.Fatalf("argmax %d: %f != %f", n, x[ArgMax64(x)], max)
+		}
+	}
+}
+
+func TestArgMax64(t *testing.T) {
+	paths(t, testArgMax64)
+}
+
+func testArgMax64(t *testing.T) {
+	nan, inf := math.NaN(), math.Inf(1)
+	tests := []struct {
+		x     []float64
+		index int
+	}{
+		{nil, -1},
+		{[]float64{nan}, -1},
+		{[]float64{nan, nan, nan, nan, nan}, -1},
+		{[]float64{-inf, -inf}, 0},
+		{[]float64{nan, 1, 3, nan, 3, 2}, 2},
+		{[]float64{1, 2, 3, 4, 5, 6, 7, 8, 9, inf}, 9},
+		{[]float64{5, 1, 2, 3, 4, 5, 6, 7, 8, 1, 8}, 8},
+		{[]float64{0, 1, 2, 3, 4, 5, 6, 7, nan, 1, 2}, 7},
+	}
+	for _, test := range tests {
+		if index := ArgMax64(test.x); index != test.index {
+			t.Fatalf("%v: %d != %d", test.x, index, test.index)
+		}
+	}
+}
+
+func BenchmarkDot64(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	x, y := random(rng, Size), random(rng, Size)
+	for b.Loop() {
+		dot64(x, y)
+	}
+}
+
+func BenchmarkVectorDot64(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	x, y := random(rng, Size), random(rng, Size)
+	for b.Loop() {
+		Dot64(x, y)
+	}
+}
@@ -1,9 +1,11 @@
 //go:build !noasm && amd64
-// AUTO-GENERATED BY GOAT -- DO NOT EDIT
 
 package vector
 
 import "unsafe"
 
+// _mm256_dot is the kernel in floats_avx.s, which goat generated from c/floats_avx.c. The declaration
+// is written by hand because the length is passed by value
+//
 //go:noescape
-func _mm256_dot(a, b, n, ret unsafe.Pointer)
+func _mm256_dot(a, b unsafe.Pointer, n int64, ret unsafe.Pointer)
@@ -1,9 +1,11 @@
 //go:build !noasm && arm64
-// AUTO-GENERATED BY GOAT -- DO NOT EDIT
 
 package vector
 
 import "unsafe"
 
+// vdot is the kernel in floats_neon.s, which goat generated. The declaration is written by hand
+// because the length is passed by value
+//
 //go:noescape
-func vdot(a, b, n, ret unsafe.Pointer)
+func vdot(a, b unsafe.Pointer, n int64, ret unsafe.Pointer)
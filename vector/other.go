@@ -2,11 +2,36 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-//go:build 386 || wasm
-// +build 386 wasm
+//go:build noasm || !(amd64 || arm64)
+// +build noasm !amd64,!arm64
 
 package vector
 
+// simd is never set because there are no kernels for the architecture
+var simd = false
+
+// Dot computes the dot product of two float32 vectors of the same length
 func Dot(x, y []float32) float32 {
+	equal(x, y)
 	return dot(x, y)
 }
+
+func vdot64(x, y []float64) float64 {
+	return dot64(x, y)
+}
+
+func vaxpy64(a float64, x, y []float64) {
+	axpy64(a, x, y)
+}
+
+func vscale64(a float64, x []float64) {
+	scale64(a, x)
+}
+
+func vsum64(x []float64) float64 {
+	return sum64(x)
+}
+
+func vmax64(x []float64) float64 {
+	return max64(x)
+}
@@ -0,0 +1,21 @@
+// Copyright 2025 The Morpheus Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package vector implements vector kernels with AVX2 and NEON assembly, the kernels
+// are selected at run time by the features of the cpu and fall back to pure Go loops
+package vector
+
+import (
+	"errors"
+)
+
+// ErrLength means that the vectors of a kernel have different lengths
+var ErrLength = errors.New("vectors have different lengths")
+
+// equal panics with ErrLength if x and y have different lengths, the kernels read len(x) values of y
+func equal[T float32 | float64](x, y []T) {
+	if len(x) != len(y) {
+		panic(ErrLength)
+	}
+}
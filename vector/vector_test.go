@@ -0,0 +1,74 @@
+// Copyright 2025 The Morpheus Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// paths runs a test with the kernels of the cpu and then with the reference loops
+func paths(t *testing.T, test func(t *testing.T)) {
+	detected := simd
+	defer func() {
+		simd = detected
+	}()
+	for _, simd = range []bool{detected, false} {
+		t.Run(fmt.Sprintf("simd=%t", simd), test)
+	}
+}
+
+func TestPaths(t *testing.T) {
+	paths(t, func(t *testing.T) {
+		rng := rand.New(rand.NewSource(1))
+		for _, n := range lengths {
+			x, y := make([]float32, n), make([]float32, n)
+			scale := 1.0
+			for i := range x {
+				x[i], y[i] = float32(rng.NormFloat64()), float32(rng.NormFloat64())
+				scale += math.Abs(float64(x[i] * y[i]))
+			}
+			if a, b := Dot(x, y), dot(x, y); math.Abs(float64(a-b)) > 1e-5*scale {
+				t.Fatalf("dot %d: %f != %f", n, a, b)
+			}
+		}
+	})
+}
+
+// panics returns the value a function panics with
+func panics(f func()) (value any) {
+	defer func() {
+		value = recover()
+	}()
+	f()
+	return nil
+}
+
+func TestEdgeCases(t *testing.T) {
+	paths(t, func(t *testing.T) {
+		if Dot(nil, nil) != 0 || Dot([]float32{}, []float32{}) != 0 || Dot64(nil, nil) != 0 {
+			t.Fatal("the dot product of empty vectors is not zero")
+		}
+		if Sum64(nil) != 0 || Norm64(nil) != 0 || ArgMax64(nil) != -1 {
+			t.Fatal("empty vector")
+		}
+		Axpy64(1, nil, nil)
+		Scale64(1, nil)
+
+		for name, f := range map[string]func(){
+			"dot":      func() { Dot(make([]float32, 9), make([]float32, 8)) },
+			"dot64":    func() { Dot64(make([]float64, 3), make([]float64, 4)) },
+			"axpy64":   func() { Axpy64(1, make([]float64, 4), make([]float64, 3)) },
+			"dotempty": func() { Dot(nil, make([]float32, 1)) },
+		} {
+			if err, ok := panics(f).(error); !ok || !errors.Is(err, ErrLength) {
+				t.Fatalf("%s: %v is not ErrLength", name, err)
+			}
+		}
+	})
+}